@@ -0,0 +1,40 @@
+package dexter
+
+// SetDescription tells what the target is for, e.g. "flushes payment
+// batches to the ledger"
+func (t *Target) SetDescription(description string) {
+	t.mu.Lock()
+	t.description = description
+	t.mu.Unlock()
+}
+
+// Description returns what SetDescription set
+func (t *Target) Description() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.description
+}
+
+// SetOwner records who owns the target's code, e.g. "team-payments", so a
+// target timing out in the logs, the debug page or the shutdown report
+// tells on-call whom to page
+func (t *Target) SetOwner(owner string) {
+	t.mu.Lock()
+	t.owner = owner
+	t.mu.Unlock()
+}
+
+// Owner returns what SetOwner set
+func (t *Target) Owner() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.owner
+}
+
+// label is the target's name for log lines, with its owner if it has one
+func (t *Target) label() string {
+	if owner := t.Owner(); owner != "" {
+		return t.name + " (owner " + owner + ")"
+	}
+	return t.name
+}
@@ -0,0 +1,113 @@
+package dexter
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBatcherClosed is returned when putting items into a Batcher that was killed
+var ErrBatcherClosed = errors.New("batcher is closed")
+
+// Batcher is a Target collecting items into batches of up to size items,
+// handing a batch to flush once it is full or interval has passed.  When
+// killed it stops accepting items and flushes the partial batch it holds
+// before the target counts as stopped, the final flush error is reported
+// like any closer error.
+type Batcher struct {
+	*Target
+	items    chan interface{}
+	size     int
+	interval time.Duration
+	flush    func(batch []interface{}) error
+	final    chan error
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewBatcher starts a batcher, track it with Dexter like any other target
+func NewBatcher(name string, size int, interval time.Duration,
+	flush func(batch []interface{}) error) *Batcher {
+	b := &Batcher{
+		Target:   NewTarget(name),
+		items:    make(chan interface{}, size),
+		size:     size,
+		interval: interval,
+		flush:    flush,
+		final:    make(chan error, 1),
+	}
+	b.TrackCloser(batcherCloser{b})
+	b.Add(1)
+	go b.run()
+	return b
+}
+
+// Put adds item to the current batch, it returns ErrBatcherClosed once the
+// batcher has been killed
+func (b *Batcher) Put(item interface{}) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrBatcherClosed
+	}
+	b.items <- item
+	return nil
+}
+
+func (b *Batcher) run() {
+	defer b.Done()
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	batch := make([]interface{}, 0, b.size)
+	send := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := b.flush(batch)
+		batch = make([]interface{}, 0, b.size)
+		return err
+	}
+
+	for {
+		select {
+		case item, ok := <-b.items:
+			if !ok {
+				b.final <- send()
+				return
+			}
+			batch = append(batch, item)
+			if len(batch) < b.size {
+				continue
+			}
+		case <-ticker.C:
+		}
+		if err := send(); err != nil {
+			dlog.Printf("Error flushing batch in %s: %v\n", b.name, err)
+		}
+	}
+}
+
+// batcherCloser stops intake and waits for the final partial batch
+type batcherCloser struct {
+	b *Batcher
+}
+
+func (c batcherCloser) Close() error {
+	b := c.b
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.items)
+	b.mu.Unlock()
+
+	if err := <-b.final; err != nil {
+		return fmt.Errorf("flushing final batch: %w", err)
+	}
+	return nil
+}
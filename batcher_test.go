@@ -0,0 +1,26 @@
+package dexter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBatcherFlushesPartialBatch(t *testing.T) {
+	var batches [][]interface{}
+	b := NewBatcher("batcher", 3, time.Hour, func(batch []interface{}) error {
+		batches = append(batches, batch)
+		return nil
+	})
+
+	for i := 0; i < 5; i++ {
+		b.Put(i)
+	}
+	b.Kill()
+
+	if len(batches) != 2 || len(batches[0]) != 3 || len(batches[1]) != 2 {
+		t.Errorf("unexpected batches %v", batches)
+	}
+	if err := b.Put(5); err != ErrBatcherClosed {
+		t.Errorf("put after kill returned %v", err)
+	}
+}
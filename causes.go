@@ -0,0 +1,67 @@
+package dexter
+
+// SetCausePriority sets the priority of a shutdown cause, a signal's name
+// such as "terminated" or a reason passed to Shutdown.  Causes arriving
+// while a shutdown runs don't start another one, they are recorded in the
+// report's Causes.  One with a higher priority than every cause before it
+// switches the targets not killed yet to the plan bound to it, e.g. to
+// upgrade a soft drain to a fast plan on a preemption notice.  Causes
+// default to priority 0.
+func (d *Dexter) SetCausePriority(cause string, priority int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.priorities == nil {
+		d.priorities = map[string]int{}
+	}
+	d.priorities[cause] = priority
+}
+
+// causePriority returns the priority set for cause
+func (d *Dexter) causePriority(cause string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.priorities[cause]
+}
+
+// collectCauses records the signals and Shutdown calls arriving while the
+// shutdown started by first runs, until stop is called.  upgrade is called
+// for every cause with a higher priority than all before it.
+func (d *Dexter) collectCauses(first trigger, report *Report, upgrade func(trigger)) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	top := d.causePriority(first.reason)
+	go labelled("", "causes", func() {
+		defer close(finished)
+		for {
+			var trig trigger
+			select {
+			case sig := <-d.waiter:
+				trig = trigger{signal: sig, reason: sig.String()}
+			case trig = <-d.triggers:
+			case <-done:
+				return
+			}
+			d.mu.Lock()
+			seen := false
+			for _, cause := range report.Causes {
+				seen = seen || cause == trig.reason
+			}
+			d.mu.Unlock()
+			if seen {
+				continue
+			}
+			d.logf("Shutdown already running, also caused by %s\n", trig.reason)
+			if priority := d.causePriority(trig.reason); priority > top {
+				top = priority
+				upgrade(trig)
+			}
+			d.mu.Lock()
+			report.Causes = append(report.Causes, trig.reason)
+			d.mu.Unlock()
+		}
+	})
+	return func() {
+		close(done)
+		<-finished
+	}
+}
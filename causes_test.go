@@ -0,0 +1,53 @@
+package dexter
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCausePriorityUpgradesPlan(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.SetCausePriority("preemption", 10)
+	dex.BindReasonPlan("preemption", KillPlan{
+		Name: "fast",
+		Skip: func(target *Target) bool { return target.Phase() == PhaseFlush },
+	})
+
+	causes := func(n int) {
+		for len(dex.reportSoFar().Causes) < n {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	ingress := NewTarget("ingress")
+	ingress.TrackCloser(closerFunc(func() error {
+		dex.SimulateSignal(syscall.SIGTERM)
+		causes(2)
+		dex.Shutdown("preemption")
+		causes(3)
+		dex.Shutdown("preemption")
+		return nil
+	}))
+	flushed := false
+	flush := NewTarget("flush")
+	flush.TrackCloser(closerFunc(func() error {
+		flushed = true
+		return nil
+	}))
+	dex.TrackPhase(PhaseIngress, ingress)
+	dex.TrackPhase(PhaseFlush, flush)
+
+	dex.Shutdown("deploy")
+	dex.WaitAndKill()
+	report := dex.LastReport()
+	if want := []string{"deploy", "terminated", "preemption"}; !reflect.DeepEqual(report.Causes, want) {
+		t.Errorf("causes %v, want %v", report.Causes, want)
+	}
+	if report.Plan != "fast" || flushed {
+		t.Errorf("plan %s, flushed %v", report.Plan, flushed)
+	}
+	if report.Reason != "deploy" {
+		t.Errorf("reason %q", report.Reason)
+	}
+}
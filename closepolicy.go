@@ -0,0 +1,41 @@
+package dexter
+
+// ClosePolicy decides whether a target's closers are closed before or
+// after its WaitGroup drained
+type ClosePolicy int
+
+const (
+	// CloseThenWait closes closers and channels, then waits for the
+	// WaitGroup.  Workers notice the shutdown through their closed
+	// resources.  It is the default.
+	CloseThenWait ClosePolicy = iota
+	// WaitThenClose runs funcs and closes channels, waits for the
+	// WaitGroup, then rolls back transactions and closes closers, so
+	// workers drain their input still using connections and files.
+	WaitThenClose
+)
+
+func (p ClosePolicy) String() string {
+	switch p {
+	case CloseThenWait:
+		return "close-then-wait"
+	case WaitThenClose:
+		return "wait-then-close"
+	}
+	return "unknown"
+}
+
+// SetClosePolicy sets the order the target's resources are released in,
+// see ClosePolicy
+func (t *Target) SetClosePolicy(policy ClosePolicy) {
+	t.mu.Lock()
+	t.closePolicy = policy
+	t.mu.Unlock()
+}
+
+// ClosePolicy returns the target's close policy
+func (t *Target) ClosePolicy() ClosePolicy {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.closePolicy
+}
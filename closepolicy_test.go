@@ -0,0 +1,31 @@
+package dexter
+
+import "testing"
+
+func TestWaitThenClose(t *testing.T) {
+	target := NewTarget("workers")
+	target.SetClosePolicy(WaitThenClose)
+	jobs := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		jobs <- i
+	}
+	target.TrackChannel(jobs)
+	conn := &countingCloser{}
+	target.TrackCloser(conn)
+
+	var used []int
+	target.Go(func() error {
+		for job := range jobs {
+			if conn.closes > 0 {
+				t.Errorf("connection closed before job %d", job)
+			}
+			used = append(used, job)
+		}
+		return nil
+	})
+
+	target.Kill()
+	if len(used) != 3 || conn.closes != 1 {
+		t.Errorf("processed %v, closed the connection %d times", used, conn.closes)
+	}
+}
@@ -0,0 +1,166 @@
+package dexter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ContextCloser is implemented by resources which can tear down within a
+// deadline.  A tracked closer implementing it is closed with
+// CloseWithContext instead of Close, ctx is done once the target's
+// deadline passed, it never is for targets without one.
+type ContextCloser interface {
+	CloseWithContext(ctx context.Context) error
+}
+
+// closeContext closes closer, preferring CloseWithContext when it has it
+func closeContext(ctx context.Context, closer io.Closer) error {
+	if cc, ok := closer.(ContextCloser); ok {
+		return cc.CloseWithContext(ctx)
+	}
+	return closer.Close()
+}
+
+// KillError holds the errors returned while a target was killed
+type KillError struct {
+	Target  string
+	Errs    []error
+	Overrun bool
+}
+
+func (e *KillError) Error() string {
+	var msgs []string
+	if e.Overrun {
+		msgs = append(msgs, "overran its deadline")
+	}
+	for _, err := range e.Errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("dexter: target %s: %s", e.Target, strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors for errors.Is and errors.As
+func (e *KillError) Unwrap() []error {
+	return e.Errs
+}
+
+// Is reports whether one of the errors is target, errors.Is only follows
+// Unwrap() []error since Go 1.20
+func (e *KillError) Is(target error) bool {
+	return isAny(e.Errs, target)
+}
+
+// As finds the first of the errors matching target, see Is
+func (e *KillError) As(target interface{}) bool {
+	return asAny(e.Errs, target)
+}
+
+// isAny reports whether errors.Is matches any of errs with target
+func isAny(errs []error, target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// asAny is errors.As for the first of errs matching target
+func asAny(errs []error, target interface{}) bool {
+	for _, err := range errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ShutdownError is returned by Dexter.Close when the shutdown was not
+// clean, Report tells what went wrong
+type ShutdownError struct {
+	Report *Report
+}
+
+func (e *ShutdownError) Error() string {
+	var msgs []string
+	for _, err := range e.Report.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	for _, target := range e.Report.Targets {
+		if target.Overrun || len(target.Errors) > 0 {
+			kerr := &KillError{Target: target.Name, Errs: target.Errors, Overrun: target.Overrun}
+			msgs = append(msgs, kerr.Error())
+		}
+	}
+	return "dexter: shutdown failed: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the shutdown for errors.Is and errors.As
+func (e *ShutdownError) Unwrap() []error {
+	errs := append([]error(nil), e.Report.Errors...)
+	for _, target := range e.Report.Targets {
+		errs = append(errs, target.Errors...)
+	}
+	return errs
+}
+
+// Is reports whether one of the errors of the shutdown is target
+func (e *ShutdownError) Is(target error) bool {
+	return isAny(e.Unwrap(), target)
+}
+
+// As finds the first of the errors of the shutdown matching target
+func (e *ShutdownError) As(target interface{}) bool {
+	return asAny(e.Unwrap(), target)
+}
+
+// Close kills the target and waits for it to drain, like Kill, and returns
+// a *KillError if anything failed, so a target can be handed to anything
+// managing io.Closers.  Closing it again only reports what its goroutines
+// returned.
+func (t *Target) Close() error {
+	errs, overrun := killTarget(t, limit{}, nil)
+	t.repanic()
+	if len(errs) == 0 && !overrun {
+		return nil
+	}
+	return &KillError{Target: t.name, Errs: errs, Overrun: overrun}
+}
+
+// Close runs the full shutdown sequence right away, as Shutdown("close")
+// would, and returns a *ShutdownError unless it was clean.  A WaitAndKill
+// waiting for a signal returns, signals are released.  Only the first call
+// shuts down, later ones return its result.  When a shutdown already
+// started, e.g. from WaitAndKill, Close waits for it and returns its result
+// instead, so a deferred Close is harmless.
+func (d *Dexter) Close() error {
+	d.closeOnce.Do(func() {
+		if d.shutDown() {
+			// wait for a shutdown still in progress
+			d.cycle.Lock()
+			d.cycle.Unlock()
+		} else {
+			d.shutdown(trigger{reason: "close"})
+		}
+		close(d.closed)
+		d.ReleaseSignals()
+		if report := d.LastReport(); !report.Clean() {
+			d.closeErr = &ShutdownError{Report: report}
+		}
+	})
+	return d.closeErr
+}
+
+// shutDown reports whether a shutdown, other than a restart, is running or
+// completed
+func (d *Dexter) shutDown() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.active != nil {
+		return !d.active.restart
+	}
+	return d.report != nil && !d.report.restart
+}
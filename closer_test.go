@@ -0,0 +1,141 @@
+package dexter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestTargetClose(t *testing.T) {
+	boom := errors.New("boom")
+	var target io.Closer = NewTarget("broken")
+	target.(*Target).TrackCloser(closerFunc(func() error { return boom }))
+
+	err := target.Close()
+	if !errors.Is(err, boom) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, ok := err.(*KillError); !ok {
+		t.Errorf("expected a *KillError, got %T", err)
+	}
+
+	if err := target.Close(); err != nil {
+		t.Errorf("second close returned %v", err)
+	}
+}
+
+func TestKillErrorIsAs(t *testing.T) {
+	// errors.Is and errors.As only follow Unwrap() []error since Go 1.20,
+	// the methods are called directly
+	err := &KillError{Target: "db", Errs: []error{io.EOF, &os.PathError{Op: "close", Err: io.ErrClosedPipe}}}
+	if !err.Is(io.EOF) || !err.Is(io.ErrClosedPipe) || err.Is(io.ErrUnexpectedEOF) {
+		t.Error("Is does not follow the errors")
+	}
+	var perr *os.PathError
+	if !err.As(&perr) || perr.Op != "close" {
+		t.Error("As does not follow the errors")
+	}
+}
+
+func TestDexterClose(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	inner := NewTarget("inner")
+	dex.Track(inner)
+	waiting := make(chan struct{})
+	go func() {
+		dex.WaitAndKill()
+		close(waiting)
+	}()
+
+	var closer io.Closer = dex
+	if err := closer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if inner.State() != TargetStopped {
+		t.Errorf("target is %v after close", inner.State())
+	}
+	select {
+	case <-waiting:
+	case <-time.After(time.Second):
+		t.Error("WaitAndKill still waiting after close")
+	}
+	if report := dex.LastReport(); report.Reason != "close" {
+		t.Errorf("unexpected reason %q", report.Reason)
+	}
+}
+
+func TestDexterCloseError(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	target := NewTarget("broken")
+	target.TrackCloser(closerFunc(func() error { return io.ErrUnexpectedEOF }))
+	dex.Track(target)
+
+	err := dex.Close()
+	if _, ok := err.(*ShutdownError); !ok || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("unexpected error %v", err)
+	}
+	var kerr *KillError
+	if serr := err.(*ShutdownError); !serr.Is(io.ErrUnexpectedEOF) || serr.As(&kerr) {
+		t.Error("ShutdownError.Is and As don't follow its errors")
+	}
+	if dex.Close() != err {
+		t.Error("second close did not return the first result")
+	}
+}
+
+type ctxCloser struct {
+	closed   bool
+	deadline time.Time
+}
+
+func (c *ctxCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *ctxCloser) CloseWithContext(ctx context.Context) error {
+	c.deadline, _ = ctx.Deadline()
+	return nil
+}
+
+func TestContextCloser(t *testing.T) {
+	target := NewTarget("ctx")
+	target.SetDeadline(time.Minute, OverrunSkip)
+	closer := &ctxCloser{}
+	target.TrackCloser(closer)
+
+	target.Kill()
+	if closer.closed {
+		t.Error("Close was called instead of CloseWithContext")
+	}
+	if left := time.Until(closer.deadline); left <= 0 || left > time.Minute {
+		t.Errorf("closer got %v of the deadline", left)
+	}
+}
+
+func TestDexterCloseAfterShutdown(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	target := NewTarget("broken")
+	target.TrackCloser(closerFunc(func() error { return io.ErrUnexpectedEOF }))
+	dex.Track(target)
+	dex.exitFunc = func(int) {}
+
+	dex.SimulateSignal(syscall.SIGTERM)
+	dex.WaitAndKill()
+	first := dex.LastReport()
+
+	err := dex.Close()
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("close did not return the shutdown's result: %v", err)
+	}
+	if dex.LastReport() != first {
+		t.Errorf("close replaced the report, reason %q", dex.LastReport().Reason)
+	}
+	if n := len(dex.History()); n != 1 {
+		t.Errorf("%d operations in the history, want 1", n)
+	}
+}
@@ -0,0 +1,115 @@
+// Command dexterctl talks to a process serving dexter's control socket,
+// see Dexter.ServeControl.
+//
+// Usage:
+//
+//	dexterctl [-socket path] status
+//	dexterctl [-socket path] snapshot
+//	dexterctl [-socket path] plan
+//	dexterctl [-socket path] shutdown [-reason deploy]
+//	dexterctl [-socket path] kill <target>
+//	dexterctl [-socket path] stacks
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const usage = `usage: dexterctl [-socket path] <command>
+
+commands:
+  status                   show targets, their states and shutdown progress
+  snapshot                 print the targets and their metadata as JSON
+  plan                     show the kill order and effective deadlines
+  shutdown [-reason text]  start a graceful shutdown
+  kill <target>            kill a single target
+  stacks                   dump the stacks of all goroutines
+`
+
+func main() {
+	socket := flag.String("socket", defaultSocket(), "path of the control socket")
+	timeout := flag.Duration("timeout", 10*time.Second, "how long to wait for an answer")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	command, err := parseCommand(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dexterctl:", err)
+		os.Exit(2)
+	}
+	if err := run(*socket, *timeout, command, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "dexterctl:", err)
+		os.Exit(1)
+	}
+}
+
+// defaultSocket is $DEXTER_SOCKET, or dexter.sock in the temp directory
+func defaultSocket() string {
+	if path := os.Getenv("DEXTER_SOCKET"); path != "" {
+		return path
+	}
+	return os.TempDir() + string(os.PathSeparator) + "dexter.sock"
+}
+
+// parseCommand turns the command line arguments into a protocol command
+func parseCommand(args []string) (string, error) {
+	switch args[0] {
+	case "status", "snapshot", "plan", "stacks":
+		if len(args) > 1 {
+			return "", fmt.Errorf("%s takes no arguments", args[0])
+		}
+		return args[0], nil
+	case "shutdown":
+		flags := flag.NewFlagSet("shutdown", flag.ContinueOnError)
+		reason := flags.String("reason", "dexterctl", "reason recorded in the shutdown report")
+		if err := flags.Parse(args[1:]); err != nil {
+			return "", err
+		}
+		return "shutdown " + *reason, nil
+	case "kill":
+		if len(args) != 2 {
+			return "", fmt.Errorf("kill takes exactly one target name")
+		}
+		return "kill " + args[1], nil
+	}
+	return "", fmt.Errorf("unknown command %q", args[0])
+}
+
+// run sends command over the socket and copies the answer to out
+func run(socket string, timeout time.Duration, command string, out io.Writer) error {
+	conn, err := net.DialTimeout("unix", socket, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err := fmt.Fprintln(conn, command); err != nil {
+		return err
+	}
+	r := bufio.NewReader(conn)
+	status, err := r.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("reading answer: %v", err)
+	}
+	status = strings.TrimSpace(status)
+	if status != "ok" {
+		return fmt.Errorf("%s", strings.TrimPrefix(status, "error "))
+	}
+	_, err = io.Copy(out, r)
+	return err
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ceocoder/dexter"
+)
+
+func TestParseCommand(t *testing.T) {
+	for _, tc := range []struct {
+		args    []string
+		command string
+		err     bool
+	}{
+		{[]string{"status"}, "status", false},
+		{[]string{"plan"}, "plan", false},
+		{[]string{"stacks", "now"}, "", true},
+		{[]string{"shutdown"}, "shutdown dexterctl", false},
+		{[]string{"shutdown", "-reason", "deploy"}, "shutdown deploy", false},
+		{[]string{"shutdown", "-force"}, "", true},
+		{[]string{"kill", "db"}, "kill db", false},
+		{[]string{"kill"}, "", true},
+		{[]string{"restart"}, "", true},
+	} {
+		command, err := parseCommand(tc.args)
+		if (err != nil) != tc.err || command != tc.command {
+			t.Errorf("%v: got %q, %v", tc.args, command, err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dexterctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "ctl.sock")
+
+	dex := dexter.NewDexter(dexter.WithManualTrigger())
+	db := dexter.NewTarget("db")
+	dex.Track(db)
+	l, err := dex.ServeControl(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	var out bytes.Buffer
+	if err := run(socket, time.Second, "status", &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "db") {
+		t.Errorf("status does not list the target:\n%s", out.String())
+	}
+	if err := run(socket, time.Second, "kill nope", &out); err == nil {
+		t.Error("killing an unknown target succeeded")
+	}
+	if err := run(socket, time.Second, "kill db", &out); err != nil {
+		t.Fatal(err)
+	}
+	if db.State() != dexter.TargetStopped {
+		t.Errorf("target is %v after kill", db.State())
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// dexterPath is the import path of the dexter package
+const dexterPath = "github.com/ceocoder/dexter"
+
+// Analyzer reports the goroutines, channels and closers of a package which
+// are never handed to a dexter.Target, see the package documentation
+var Analyzer = &analysis.Analyzer{
+	Name: "dexterlint",
+	Doc:  "report goroutines, channels and closers never handed to a dexter.Target",
+	Run:  run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, d := range check(pass.Fset, pass.Files, pass.TypesInfo) {
+		pass.Reportf(d.pos, "%s", d.message)
+	}
+	return nil, nil
+}
+
+// diagnostic is a finding at a position
+type diagnostic struct {
+	pos     token.Pos
+	message string
+}
+
+// checker finds resources created in a package which are never handed to
+// a dexter.Target
+type checker struct {
+	info  *types.Info
+	found []diagnostic
+}
+
+// check reports the unmanaged goroutines, channels and closers created in
+// files, packages which don't import dexter are skipped.  The analysis is
+// local to each function and deliberately conservative about resources
+// which leave the function, see the package documentation.
+func check(fset *token.FileSet, files []*ast.File, info *types.Info) []diagnostic {
+	c := &checker{info: info}
+	for _, file := range files {
+		if !imports(file, dexterPath) || strings.HasSuffix(fset.Position(file.Pos()).Filename, "_test.go") {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+				c.function(fn.Body)
+			}
+		}
+	}
+	return c.found
+}
+
+func imports(file *ast.File, path string) bool {
+	for _, spec := range file.Imports {
+		if strings.Trim(spec.Path.Value, `"`) == path {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) report(pos token.Pos, message string) {
+	c.found = append(c.found, diagnostic{pos: pos, message: message})
+}
+
+// function checks a function body, including the function literals in it
+func (c *checker) function(body *ast.BlockStmt) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.GoStmt:
+			if !c.callsTargetDone(n.Call) {
+				c.report(n.Pos(), "goroutine is not managed by a dexter.Target, start it with Target.Go")
+			}
+		case *ast.AssignStmt:
+			if n.Tok != token.DEFINE {
+				return true
+			}
+			for i, lhs := range n.Lhs {
+				// f, err := os.Open(name) has a single value for both
+				value := n.Rhs[0]
+				if len(n.Rhs) == len(n.Lhs) {
+					value = n.Rhs[i]
+				}
+				c.local(body, lhs, value)
+			}
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if i < len(n.Values) {
+					c.local(body, name, n.Values[i])
+				}
+			}
+		}
+		return true
+	})
+}
+
+// local checks a variable declared in body and initialized with value
+func (c *checker) local(body *ast.BlockStmt, lhs ast.Expr, value ast.Expr) {
+	ident, ok := lhs.(*ast.Ident)
+	if !ok || ident.Name == "_" {
+		return
+	}
+	obj, ok := c.info.Defs[ident].(*types.Var)
+	if !ok {
+		return
+	}
+
+	var kind string
+	switch {
+	case isMakeChan(value):
+		kind = "channel"
+	case isCall(value) && isCloser(obj.Type()):
+		kind = "closer"
+	default:
+		return
+	}
+	if !c.handled(body, obj) {
+		c.report(ident.Pos(), kind+" "+ident.Name+" is never registered with a dexter.Target")
+	}
+}
+
+// handled reports whether the function registers obj with a target,
+// closes it itself or lets it escape, where it might be registered
+func (c *checker) handled(body *ast.BlockStmt, obj *types.Var) bool {
+	uses := func(expr ast.Expr) bool {
+		ident, ok := unparen(expr).(*ast.Ident)
+		return ok && c.info.Uses[ident] == obj
+	}
+	handled := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if handled {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			// close(ch)
+			if fn, ok := n.Fun.(*ast.Ident); ok && fn.Name == "close" && len(n.Args) == 1 && uses(n.Args[0]) {
+				handled = true
+			}
+			if sel, ok := n.Fun.(*ast.SelectorExpr); ok {
+				// c.Close()
+				if sel.Sel.Name == "Close" && uses(sel.X) {
+					handled = true
+				}
+				// target.TrackCloser(c), dex.Track(target)
+				if c.isRegistration(sel) {
+					for _, arg := range n.Args {
+						if uses(arg) {
+							handled = true
+						}
+					}
+				}
+			}
+		case *ast.ReturnStmt:
+			for _, result := range n.Results {
+				if uses(result) {
+					handled = true
+				}
+			}
+		case *ast.AssignStmt:
+			// stored somewhere which outlives the function
+			for i, rhs := range n.Rhs {
+				if i < len(n.Lhs) && uses(rhs) {
+					if _, local := n.Lhs[i].(*ast.Ident); !local {
+						handled = true
+					}
+				}
+			}
+		case *ast.CompositeLit:
+			for _, elt := range n.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					elt = kv.Value
+				}
+				if uses(elt) {
+					handled = true
+				}
+			}
+		}
+		return true
+	})
+	return handled
+}
+
+// isRegistration reports whether sel selects a function or method of
+// dexter, which are assumed to take over resources passed to them, e.g.
+// TrackCloser, TrackChannel or Dexter.Track
+func (c *checker) isRegistration(sel *ast.SelectorExpr) bool {
+	fn, ok := c.info.Uses[sel.Sel].(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == dexterPath
+}
+
+// callsTargetDone reports whether the goroutine started by call signals a
+// dexter.Target's WaitGroup itself
+func (c *checker) callsTargetDone(call *ast.CallExpr) bool {
+	lit, ok := call.Fun.(*ast.FuncLit)
+	if !ok {
+		return false
+	}
+	found := false
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Done" {
+			return true
+		}
+		if selection, ok := c.info.Selections[sel]; ok {
+			fn := selection.Obj()
+			if fn.Pkg() != nil && fn.Pkg().Path() == dexterPath {
+				found = true
+			}
+		}
+		return true
+	})
+	return found
+}
+
+func isMakeChan(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return false
+	}
+	fn, ok := call.Fun.(*ast.Ident)
+	if !ok || fn.Name != "make" {
+		return false
+	}
+	_, ok = call.Args[0].(*ast.ChanType)
+	return ok
+}
+
+func isCall(expr ast.Expr) bool {
+	_, ok := expr.(*ast.CallExpr)
+	return ok
+}
+
+// isCloser reports whether typ has a Close() error method
+func isCloser(typ types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, "Close")
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false
+	}
+	return types.Identical(sig.Results().At(0).Type(), types.Universe.Lookup("error").Type())
+}
+
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		paren, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = paren.X
+	}
+}
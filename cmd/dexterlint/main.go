@@ -0,0 +1,30 @@
+// Command dexterlint flags goroutines, channels and closers created in
+// packages which import dexter but never handed to a dexter.Target, so
+// they would be left running or open at shutdown.
+//
+// Run it through go vet:
+//
+//	go build -o dexterlint github.com/ceocoder/dexter/cmd/dexterlint
+//	go vet -vettool=$(pwd)/dexterlint ./...
+//
+// or directly on packages:
+//
+//	dexterlint ./server ./worker
+//
+// The analysis is local to each function.  A goroutine is managed when it
+// is started with Target.Go, or its function literal calls Done on a
+// Target.  A channel made with make, or a closer returned from a call, is
+// managed when the function passes it to any function or method of dexter,
+// such as TrackChannel, TrackCloser or Dexter.Track, closes it itself,
+// returns it or stores it in a field, element or composite literal, where
+// another function may register it.  Test files are not checked.
+//
+// dexterlint is a module of its own so the dexter module keeps depending on
+// the standard library only.
+package main
+
+import "golang.org/x/tools/go/analysis/singlechecker"
+
+func main() {
+	singlechecker.Main(Analyzer)
+}
@@ -0,0 +1,36 @@
+package example
+
+import (
+	"net"
+
+	"github.com/ceocoder/dexter"
+)
+
+type server struct {
+	l net.Listener
+}
+
+func Wire(t *dexter.Target, s *server) error {
+	leaked := make(chan int) // want `channel leaked is never registered with a dexter.Target`
+	tracked := make(chan int)
+	t.TrackChannel(tracked)
+	local := make(chan struct{})
+	go func() { // want `goroutine is not managed by a dexter.Target, start it with Target.Go`
+		close(local)
+	}()
+	<-local
+	go func() {
+		defer t.Done()
+		for range leaked {
+		}
+	}()
+
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return err
+	}
+	s.l = l
+	orphan, _ := net.Listen("tcp", ":0") // want `closer orphan is never registered with a dexter.Target`
+	_ = orphan
+	return nil
+}
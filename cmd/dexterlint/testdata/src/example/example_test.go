@@ -0,0 +1,14 @@
+package example
+
+import (
+	"testing"
+
+	"github.com/ceocoder/dexter"
+)
+
+func TestWire(t *testing.T) {
+	var target dexter.Target
+	go func() {}()
+	_ = make(chan int)
+	Wire(&target, &server{})
+}
@@ -0,0 +1,10 @@
+// Package dexter has just enough of dexter to type check the examples
+package dexter
+
+import "io"
+
+type Target struct{}
+
+func (t *Target) TrackChannel(ch interface{}) error { return nil }
+func (t *Target) TrackCloser(c io.Closer)           {}
+func (t *Target) Done()                             {}
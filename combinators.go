@@ -0,0 +1,141 @@
+package dexter
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+)
+
+// WithTimeout wraps c so its Close gives up after d and returns a
+// *CloseTimeoutError, the Close of c is left running
+func WithTimeout(c io.Closer, d time.Duration) io.Closer {
+	return &timedCloser{closer: c, timeout: d}
+}
+
+// WithRetries wraps c so a failed Close is retried up to n more times,
+// waiting backoff before the first retry and twice as long before each one
+// after.  Retries stop once the target's deadline passed, the last error is
+// returned.
+func WithRetries(c io.Closer, n int, backoff time.Duration) io.Closer {
+	return &retryCloser{closer: c, retries: n, backoff: backoff}
+}
+
+// Sequence returns a closer which closes closers one after the other, in
+// order, even when some fail.  The errors are returned together, errors.Is
+// and errors.As see each of them.
+func Sequence(closers ...io.Closer) io.Closer {
+	return &sequenceCloser{closers: closers}
+}
+
+type retryCloser struct {
+	closer  io.Closer
+	retries int
+	backoff time.Duration
+}
+
+func (c *retryCloser) Close() error {
+	return c.CloseWithContext(context.Background())
+}
+
+// CloseWithContext retries until ctx is done
+func (c *retryCloser) CloseWithContext(ctx context.Context) error {
+	err := closeContext(ctx, c.closer)
+	wait := c.backoff
+	for i := 0; err != nil && i < c.retries; i++ {
+		dlog.Printf("Closing %T failed, retrying in %v: %v\n", unwrap(c.closer), wait, err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		err = closeContext(ctx, c.closer)
+		wait *= 2
+	}
+	return err
+}
+
+func (c *retryCloser) unwrap() interface{} {
+	return unwrap(c.closer)
+}
+
+func (c *retryCloser) resourceName() string {
+	return resourceName(c.closer)
+}
+
+// ReportDetails passes through the details of the wrapped closer
+func (c *retryCloser) ReportDetails() map[string]string {
+	if detailer, ok := c.closer.(ReportDetailer); ok {
+		return detailer.ReportDetails()
+	}
+	return nil
+}
+
+type sequenceCloser struct {
+	closers []io.Closer
+}
+
+func (c *sequenceCloser) Close() error {
+	return c.CloseWithContext(context.Background())
+}
+
+// CloseWithContext hands ctx to every closer of the sequence
+func (c *sequenceCloser) CloseWithContext(ctx context.Context) error {
+	var errs closeErrors
+	for _, closer := range c.closers {
+		if err := closeContext(ctx, closer); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// ReportDetails merges the details of the closers of the sequence
+func (c *sequenceCloser) ReportDetails() map[string]string {
+	var details map[string]string
+	for _, closer := range c.closers {
+		detailer, ok := closer.(ReportDetailer)
+		if !ok {
+			continue
+		}
+		for k, v := range detailer.ReportDetails() {
+			if details == nil {
+				details = map[string]string{}
+			}
+			details[k] = v
+		}
+	}
+	return details
+}
+
+// closeErrors holds the errors of a sequence of closers
+type closeErrors []error
+
+func (e closeErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors for errors.Is and errors.As
+func (e closeErrors) Unwrap() []error {
+	return e
+}
+
+// Is reports whether one of the errors is target, errors.Is only follows
+// Unwrap() []error since Go 1.20
+func (e closeErrors) Is(target error) bool {
+	return isAny(e, target)
+}
+
+// As finds the first of the errors matching target, see Is
+func (e closeErrors) As(target interface{}) bool {
+	return asAny(e, target)
+}
@@ -0,0 +1,60 @@
+package dexter
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWithRetries(t *testing.T) {
+	calls := 0
+	flaky := closerFunc(func() error {
+		calls++
+		if calls < 3 {
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	})
+	if err := WithRetries(flaky, 2, time.Millisecond).Close(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("closed %d times, want 3", calls)
+	}
+
+	calls = 0
+	if err := WithRetries(flaky, 1, time.Millisecond).Close(); err != io.ErrUnexpectedEOF {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSequence(t *testing.T) {
+	var order []string
+	step := func(name string, err error) io.Closer {
+		return closerFunc(func() error {
+			order = append(order, name)
+			return err
+		})
+	}
+	boom := errors.New("boom")
+	target := NewTarget("sequence")
+	target.TrackCloser(Sequence(
+		step("flush", boom),
+		WithTimeout(step("close", nil), time.Second),
+		step("unlink", io.ErrClosedPipe),
+	))
+
+	errs := target.kill()
+	if len(order) != 3 || order[0] != "flush" || order[2] != "unlink" {
+		t.Errorf("closed in order %v", order)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], boom) || !errors.Is(errs[0], io.ErrClosedPipe) {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	// errors.Is only follows Unwrap() []error since Go 1.20
+	var cerrs closeErrors
+	if !errors.As(errs[0], &cerrs) || !cerrs.Is(boom) || !cerrs.Is(io.ErrClosedPipe) {
+		t.Errorf("closeErrors.Is does not follow %v", errs[0])
+	}
+}
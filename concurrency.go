@@ -0,0 +1,74 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// closeSlots bounds the Close calls in progress across the process, a nil
+// channel means unlimited
+var closeSlots struct {
+	sync.Mutex
+	ch chan struct{}
+}
+
+// SetCloseConcurrency limits how many tracked closers are closed at the
+// same time across the whole process, by every Dexter, target and shard
+// set, so a shutdown doesn't churn thousands of file descriptors or TLS
+// close_notify exchanges at once.  n of 0 removes the limit.  Closers
+// waiting for a slot give up once their target's deadline passes.
+// Closers which only close other targets, such as a ShardSet, a Target or
+// a Dexter tracked as a closer, don't take a slot.
+func SetCloseConcurrency(n int) {
+	closeSlots.Lock()
+	defer closeSlots.Unlock()
+	if n <= 0 {
+		closeSlots.ch = nil
+		return
+	}
+	closeSlots.ch = make(chan struct{}, n)
+}
+
+// closeGroup is implemented by closers which close other targets, they
+// must not hold a slot their targets' closers wait for
+type closeGroup interface {
+	closesTargets()
+}
+
+func (c shardCloser) closesTargets() {}
+
+func (t *Target) closesTargets() {}
+
+func (d *Dexter) closesTargets() {}
+
+// acquireClose waits for a close slot for closer, release must be called
+// once it was closed
+func acquireClose(ctx context.Context, closer interface{}) (release func(), err error) {
+	if _, ok := unwrap(closer).(closeGroup); ok {
+		return func() {}, nil
+	}
+	closeSlots.Lock()
+	slots := closeSlots.ch
+	closeSlots.Unlock()
+	if slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting to close %s: %w", resourceName(closer), ctx.Err())
+	}
+}
+
+// closeLimited closes closer within the process wide close concurrency
+func closeLimited(ctx context.Context, closer io.Closer) error {
+	release, err := acquireClose(ctx, closer)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return closeContext(ctx, closer)
+}
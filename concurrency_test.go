@@ -0,0 +1,68 @@
+package dexter
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCloseConcurrency(t *testing.T) {
+	SetCloseConcurrency(2)
+	defer SetCloseConcurrency(0)
+
+	var mu sync.Mutex
+	active, peak := 0, 0
+	slow := closerFunc(func() error {
+		mu.Lock()
+		if active++; active > peak {
+			peak = active
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return nil
+	})
+	var shards []*Target
+	for i := 0; i < 6; i++ {
+		shard := NewTarget(fmt.Sprintf("shard-%d", i))
+		shard.TrackCloser(slow)
+		shards = append(shards, shard)
+	}
+	set := NewShardSet("shards", len(shards), shards...)
+
+	if err := set.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if peak != 2 {
+		t.Errorf("%d closers closed at the same time, want 2", peak)
+	}
+}
+
+func TestCloseConcurrencyNestedDexter(t *testing.T) {
+	SetCloseConcurrency(1)
+	defer SetCloseConcurrency(0)
+
+	module := NewDexter(WithManualTrigger())
+	inner := NewTarget("inner")
+	inner.TrackCloser(closerFunc(func() error { return nil }))
+	module.Track(inner)
+	outer := NewTarget("module")
+	outer.TrackCloser(module)
+
+	done := make(chan struct{})
+	go func() {
+		outer.Kill()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("nested dexter deadlocked on the close slot")
+	}
+	if inner.State() != TargetStopped {
+		t.Errorf("inner target is %v", inner.State())
+	}
+}
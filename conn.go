@@ -0,0 +1,133 @@
+package dexter
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CutOffError is returned by ConnTracker.Close when active connections had
+// to be closed because they didn't finish before the deadline
+type CutOffError struct {
+	Count int
+}
+
+func (e *CutOffError) Error() string {
+	return fmt.Sprintf("cut off %d active connections", e.Count)
+}
+
+// ConnTracker keeps track of which connections are idle and which are in
+// the middle of serving something.  On Close idle connections are closed
+// right away while active ones get up to deadline to finish, connections
+// going idle in the meantime are closed as soon as they do.
+//
+// Use ConnState as http.Server.ConnState, or call Active, Idle and Forget
+// directly from a plain TCP server.
+type ConnTracker struct {
+	deadline time.Duration
+
+	mu      sync.Mutex
+	active  map[net.Conn]bool
+	closing bool
+	drained chan struct{}
+}
+
+// NewConnTracker returns a tracker giving active connections up to deadline
+// to finish, hand it to TrackCloser
+func NewConnTracker(deadline time.Duration) *ConnTracker {
+	return &ConnTracker{
+		deadline: deadline,
+		active:   map[net.Conn]bool{},
+		drained:  make(chan struct{}),
+	}
+}
+
+// ConnState records conn's state, its signature matches http.Server.ConnState
+func (c *ConnTracker) ConnState(conn net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateActive:
+		c.Active(conn)
+	case http.StateNew, http.StateIdle:
+		c.Idle(conn)
+	case http.StateHijacked, http.StateClosed:
+		c.Forget(conn)
+	}
+}
+
+// Active marks conn as busy serving something
+func (c *ConnTracker) Active(conn net.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active[conn] = true
+}
+
+// Idle marks conn as idle, if the tracker is closing conn is closed
+func (c *ConnTracker) Idle(conn net.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closing {
+		conn.Close()
+		c.forget(conn)
+		return
+	}
+	c.active[conn] = false
+}
+
+// Forget stops tracking conn, e.g. because it was closed or hijacked
+func (c *ConnTracker) Forget(conn net.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forget(conn)
+}
+
+// forget must be called with c.mu held
+func (c *ConnTracker) forget(conn net.Conn) {
+	delete(c.active, conn)
+	c.checkDrained()
+}
+
+// checkDrained must be called with c.mu held
+func (c *ConnTracker) checkDrained() {
+	if c.closing && len(c.active) == 0 {
+		select {
+		case <-c.drained:
+		default:
+			close(c.drained)
+		}
+	}
+}
+
+// Close closes idle connections, waits for active ones up to the deadline
+// and closes the rest, returning a *CutOffError if there were any
+func (c *ConnTracker) Close() error {
+	c.mu.Lock()
+	c.closing = true
+	for conn, active := range c.active {
+		if !active {
+			conn.Close()
+			delete(c.active, conn)
+		}
+	}
+	c.checkDrained()
+	c.mu.Unlock()
+
+	select {
+	case <-c.drained:
+		return nil
+	case <-time.After(c.deadline):
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := len(c.active)
+	for conn := range c.active {
+		conn.Close()
+		delete(c.active, conn)
+	}
+	if n > 0 {
+		return &CutOffError{Count: n}
+	}
+	return nil
+}
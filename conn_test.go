@@ -0,0 +1,61 @@
+package dexter
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestConnTracker(t *testing.T) {
+	tracker := NewConnTracker(20 * time.Millisecond)
+
+	idle, idleClient := net.Pipe()
+	active, activeClient := net.Pipe()
+	finishing, finishingClient := net.Pipe()
+	tracker.ConnState(idle, http.StateIdle)
+	tracker.ConnState(active, http.StateActive)
+	tracker.ConnState(finishing, http.StateActive)
+
+	go func() {
+		// finishes its request in time and goes idle
+		time.Sleep(5 * time.Millisecond)
+		tracker.ConnState(finishing, http.StateIdle)
+	}()
+
+	err, ok := tracker.Close().(*CutOffError)
+	if !ok || err.Count != 1 {
+		t.Errorf("expected one connection to be cut off, got %v", err)
+	}
+	for _, client := range []net.Conn{idleClient, activeClient, finishingClient} {
+		if _, err := client.Read(make([]byte, 1)); err == nil {
+			t.Error("connection left open")
+		}
+	}
+}
+
+func TestReportCollectsCutOffs(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	target := NewTarget("http")
+	tracker := NewConnTracker(time.Millisecond)
+	conn, _ := net.Pipe()
+	tracker.Active(conn)
+	target.TrackCloser(tracker)
+	dex.Track(target)
+
+	dex.SimulateSignal(os.Interrupt)
+	dex.WaitAndKill()
+
+	report := dex.LastReport()
+	if report == nil || report.Clean() || report.Signal != os.Interrupt {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	errs := report.Targets[0].Errors
+	if len(errs) != 1 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if cut, ok := errs[0].(*CutOffError); !ok || cut.Count != 1 {
+		t.Errorf("unexpected error %v", errs[0])
+	}
+}
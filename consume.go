@@ -0,0 +1,51 @@
+package dexter
+
+import "reflect"
+
+// Consume runs fn for every value received from ch on a goroutine
+// managed by t, see Target.Go, so the target only finishes draining once
+// the loop returned.  When ch is tracked by t the loop drains everything
+// buffered in it once t closes it.  Otherwise the loop stops as soon as t
+// is killed and values left in ch are not consumed.  Either way it stops
+// when ch is closed.
+func Consume[T any](t *Target, ch <-chan T, fn func(T)) {
+	if t.tracksChannel(ch) {
+		t.Go(func() error {
+			for v := range ch {
+				fn(v)
+			}
+			return nil
+		})
+		return
+	}
+	states := t.Watch()
+	t.Go(func() error {
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					return nil
+				}
+				fn(v)
+			case state, ok := <-states:
+				if !ok || state != TargetRunning {
+					return nil
+				}
+			}
+		}
+	})
+}
+
+// tracksChannel reports whether the target closes channel, whatever its
+// direction
+func (t *Target) tracksChannel(channel interface{}) bool {
+	ptr := reflect.ValueOf(channel).Pointer()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, tracked := range t.channels {
+		if reflect.ValueOf(tracked).Pointer() == ptr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package dexter
+
+import "testing"
+
+func TestConsumeTracked(t *testing.T) {
+	target := NewTarget("consumer")
+	jobs := make(chan int, 5)
+	target.TrackChannel(jobs)
+	for i := 0; i < 5; i++ {
+		jobs <- i
+	}
+	sum := 0
+	Consume(target, jobs, func(n int) { sum += n })
+
+	target.Kill()
+	if sum != 10 {
+		t.Errorf("consumed a sum of %d, want 10", sum)
+	}
+}
+
+func TestConsumeUntracked(t *testing.T) {
+	target := NewTarget("consumer")
+	events := make(chan string)
+	got := make(chan string)
+	Consume(target, events, func(s string) { got <- s })
+	events <- "a"
+	<-got
+
+	target.Kill()
+	if n := target.Stats().Goroutines; n != 0 {
+		t.Errorf("%d consumers survived the kill", n)
+	}
+}
@@ -0,0 +1,25 @@
+package dexter
+
+import "context"
+
+// NewDexterContext returns a Dexter which also starts shutdown when ctx is
+// done, e.g. a context from signal.NotifyContext shared with other
+// libraries.  The context's error is the shutdown reason.
+func NewDexterContext(ctx context.Context, opts ...Option) *Dexter {
+	dex := NewDexter(opts...)
+	dex.AddTrigger(ContextTrigger(ctx))
+	return dex
+}
+
+// SignalContext returns a context which is canceled as soon as shutdown
+// starts, like the one returned by signal.NotifyContext, for libraries
+// which expect the standard library idiom
+func (d *Dexter) SignalContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopping := d.Stopping()
+	go func() {
+		<-stopping
+		cancel()
+	}()
+	return ctx
+}
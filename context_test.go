@@ -0,0 +1,35 @@
+package dexter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewDexterContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	dex := NewDexterContext(ctx, WithManualTrigger())
+	dex.Track(NewTarget("db"))
+
+	cancel()
+	dex.WaitAndKill()
+	if report := dex.LastReport(); report.Reason != "context canceled" {
+		t.Errorf("unexpected reason %q", report.Reason)
+	}
+}
+
+func TestSignalContext(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	ctx := dex.SignalContext()
+	if ctx.Err() != nil {
+		t.Fatal("context done before shutdown")
+	}
+
+	dex.Shutdown("test")
+	dex.WaitAndKill()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Error("context not canceled by shutdown")
+	}
+}
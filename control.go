@@ -0,0 +1,127 @@
+package dexter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"runtime/pprof"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// controlTimeout bounds how long a control connection may take to send
+// its command
+const controlTimeout = 5 * time.Second
+
+// ServeControl listens on the unix socket at path and serves the control
+// protocol used by cmd/dexterctl until the returned io.Closer is closed.
+// A stale socket file left behind by a previous process is removed.
+//
+// The protocol is line based: the client sends a single command line,
+// "status", "snapshot", "plan", "shutdown <reason>", "kill <target>" or
+// "stacks", and the server answers with "ok" or "error <message>" on the
+// first line, followed by the command's output, then closes the
+// connection.  snapshot answers with the JSON encoded Snapshot.
+func (d *Dexter) ServeControl(path string) (io.Closer, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go d.serveControlConn(conn)
+		}
+	}()
+	return l, nil
+}
+
+func (d *Dexter) serveControlConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(controlTimeout))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && line == "" {
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		fmt.Fprintln(conn, "error empty command")
+		return
+	}
+	arg := strings.Join(fields[1:], " ")
+	switch fields[0] {
+	case "status":
+		fmt.Fprintln(conn, "ok")
+		d.writeStatus(conn)
+	case "shutdown":
+		if arg == "" {
+			arg = "control socket"
+		}
+		fmt.Fprintln(conn, "ok")
+		d.Shutdown(arg)
+	case "kill":
+		target := d.lookup(arg)
+		if target == nil {
+			fmt.Fprintf(conn, "error no target named %q is tracked\n", arg)
+			return
+		}
+		target.Kill()
+		fmt.Fprintln(conn, "ok")
+	case "snapshot":
+		fmt.Fprintln(conn, "ok")
+		enc := json.NewEncoder(conn)
+		enc.SetIndent("", "  ")
+		enc.Encode(d.Snapshot())
+	case "plan":
+		fmt.Fprintln(conn, "ok")
+		fmt.Fprint(conn, d.Plan())
+	case "stacks":
+		fmt.Fprintln(conn, "ok")
+		pprof.Lookup("goroutine").WriteTo(conn, 2)
+	default:
+		fmt.Fprintf(conn, "error unknown command %q\n", fields[0])
+	}
+}
+
+// writeStatus writes the targets in kill order and the progress of the
+// shutdown, if any, as a table
+func (d *Dexter) writeStatus(w io.Writer) {
+	fmt.Fprintf(w, "serving: %v\n", d.Serving())
+	if report := d.reportSoFar(); report != nil {
+		fmt.Fprintf(w, "shutting down: %s (plan %s) for %v, %d targets done\n",
+			report.Reason, report.Plan, report.Duration.Round(time.Millisecond), len(report.Targets))
+	} else if report := d.LastReport(); report != nil {
+		fmt.Fprintf(w, "last shutdown: %s (plan %s) took %v, clean: %v\n",
+			report.Reason, report.Plan, report.Duration.Round(time.Millisecond), report.Clean())
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tPHASE\tSTATE\tFUNCS\tTXS\tCLOSERS\tCHANNELS\tPENDING")
+	for _, target := range d.killOrder() {
+		s := target.Stats()
+		fmt.Fprintf(tw, "%s\t%v\t%v\t%d\t%d\t%d\t%d\t%d\n", target.name, target.Phase(), target.State(),
+			s.Funcs, s.Transactions, s.Closers, s.Channels, s.Pending)
+	}
+	tw.Flush()
+}
+
+// lookup returns the first tracked target called name, nil if there is none
+func (d *Dexter) lookup(name string) *Target {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if i := d.indexOf(name); i >= 0 {
+		return d.targets[i]
+	}
+	return nil
+}
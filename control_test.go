@@ -0,0 +1,68 @@
+package dexter
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServeControl(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dexter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ctl.sock")
+
+	dex := NewDexter(WithManualTrigger())
+	ingestion := NewTarget("ingestion")
+	dex.Track(ingestion)
+	l, err := dex.ServeControl(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	send := func(command string) (string, string) {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		fmt.Fprintln(conn, command)
+		r := bufio.NewReader(conn)
+		status, _ := r.ReadString('\n')
+		body, _ := ioutil.ReadAll(r)
+		return strings.TrimSpace(status), string(body)
+	}
+
+	status, body := send("status")
+	if status != "ok" || !strings.Contains(body, "ingestion") || !strings.Contains(body, "running") {
+		t.Errorf("status: %s\n%s", status, body)
+	}
+	if status, _ := send("kill nope"); !strings.HasPrefix(status, "error") {
+		t.Errorf("killing an unknown target answered %q", status)
+	}
+	if status, _ := send("kill ingestion"); status != "ok" {
+		t.Errorf("kill answered %q", status)
+	}
+	if ingestion.State() != TargetStopped {
+		t.Errorf("killed target is %v", ingestion.State())
+	}
+	if status, body := send("stacks"); status != "ok" || !strings.Contains(body, "goroutine") {
+		t.Errorf("stacks: %s\n%s", status, body)
+	}
+
+	if status, _ := send("shutdown deploy"); status != "ok" {
+		t.Errorf("shutdown answered %q", status)
+	}
+	dex.WaitAndKill()
+	if report := dex.LastReport(); report == nil || report.Reason != "deploy" {
+		t.Errorf("shutdown not started with the given reason: %+v", report)
+	}
+}
@@ -0,0 +1,157 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// OverrunPolicy decides what happens when a target takes longer than its
+// deadline to shut down
+type OverrunPolicy int
+
+const (
+	// OverrunWait keeps waiting for the target, only the force kill window
+	// applies.  This is the default.
+	OverrunWait OverrunPolicy = iota
+	// OverrunSkip abandons the target and moves on to the next one
+	OverrunSkip
+	// OverrunFallback runs the target's fallback, e.g. a hard Close instead
+	// of a graceful Shutdown, waits up to the deadline once more and then
+	// moves on to the next target
+	OverrunFallback
+	// OverrunExit force exits the process straight away
+	OverrunExit
+)
+
+func (p OverrunPolicy) String() string {
+	switch p {
+	case OverrunWait:
+		return "wait"
+	case OverrunSkip:
+		return "skip"
+	case OverrunFallback:
+		return "fallback"
+	case OverrunExit:
+		return "exit"
+	}
+	return "unknown"
+}
+
+// SetDeadline sets how long the target may take to close its resources and
+// drain, and what happens when it takes longer.  It overrides the default
+// set with Dexter.SetDefaultDeadline, a zero deadline inherits it again.
+func (t *Target) SetDeadline(deadline time.Duration, policy OverrunPolicy) {
+	t.mu.Lock()
+	t.deadline = deadline
+	t.policy = policy
+	t.mu.Unlock()
+}
+
+// SetFallback sets the function run when the target overruns its deadline
+// with the OverrunFallback policy
+func (t *Target) SetFallback(fallback func()) {
+	t.mu.Lock()
+	t.fallback = fallback
+	t.mu.Unlock()
+}
+
+// limit is the deadline a target is killed with and where it came from
+type limit struct {
+	deadline time.Duration
+	policy   OverrunPolicy
+	source   string
+}
+
+// SetDefaultDeadline sets the deadline and overrun policy of the targets
+// which don't set their own with Target.SetDeadline.  By default targets
+// have no deadline and only the force kill window applies.
+func (d *Dexter) SetDefaultDeadline(deadline time.Duration, policy OverrunPolicy) {
+	d.mu.Lock()
+	d.defaultLimit = limit{deadline: deadline, policy: policy, source: "default"}
+	d.mu.Unlock()
+}
+
+// limitFor returns the limit target is killed with
+func (d *Dexter) limitFor(target *Target) limit {
+	d.mu.Lock()
+	def := d.defaultLimit
+	d.mu.Unlock()
+	return target.limit(def)
+}
+
+// limit returns the target's own deadline, or def if it has none
+func (t *Target) limit(def limit) limit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.deadline > 0 {
+		return limit{deadline: t.deadline, policy: t.policy, source: "target"}
+	}
+	if def.deadline <= 0 {
+		return limit{source: "none"}
+	}
+	return def
+}
+
+// CloseTimeoutError is returned for a closer tracked with
+// TrackCloserTimeout which didn't return from Close in time
+type CloseTimeoutError struct {
+	Closer  io.Closer
+	Timeout time.Duration
+}
+
+func (e *CloseTimeoutError) Error() string {
+	return fmt.Sprintf("closing %s timed out after %v", resourceName(e.Closer), e.Timeout)
+}
+
+// TrackCloserTimeout tracks closer like TrackCloser, but gives up on its
+// Close after timeout, within the target's own deadline.  A closer which
+// times out is abandoned with its Close still running.  It is shorthand for
+// TrackCloser(WithTimeout(closer, timeout)).
+func (t *Target) TrackCloserTimeout(closer io.Closer, timeout time.Duration) {
+	t.TrackCloser(WithTimeout(closer, timeout))
+}
+
+// timedCloser bounds how long Close may take
+type timedCloser struct {
+	closer  io.Closer
+	timeout time.Duration
+}
+
+func (c *timedCloser) Close() error {
+	return c.CloseWithContext(context.Background())
+}
+
+// CloseWithContext bounds ctx by the timeout, for wrapped closers
+// implementing ContextCloser
+func (c *timedCloser) CloseWithContext(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- closeContext(ctx, c.closer)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return &CloseTimeoutError{Closer: c.closer, Timeout: c.timeout}
+	}
+}
+
+func (c *timedCloser) unwrap() interface{} {
+	return unwrap(c.closer)
+}
+
+func (c *timedCloser) resourceName() string {
+	return resourceName(c.closer)
+}
+
+// ReportDetails passes through the details of the wrapped closer
+func (c *timedCloser) ReportDetails() map[string]string {
+	if detailer, ok := c.closer.(ReportDetailer); ok {
+		return detailer.ReportDetails()
+	}
+	return nil
+}
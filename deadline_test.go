@@ -0,0 +1,84 @@
+package dexter
+
+import (
+	"testing"
+	"time"
+)
+
+func stuckTarget(name string) (*Target, func()) {
+	target := NewTarget(name)
+	target.Add(1)
+	return target, target.Done
+}
+
+func TestOverrunSkip(t *testing.T) {
+	target, release := stuckTarget("skip")
+	defer release()
+	target.SetDeadline(10*time.Millisecond, OverrunSkip)
+
+	if _, overrun := killTarget(target, limit{}, nil); !overrun {
+		t.Error("stuck target did not overrun")
+	}
+}
+
+func TestOverrunFallback(t *testing.T) {
+	target, release := stuckTarget("fallback")
+	target.SetDeadline(10*time.Millisecond, OverrunFallback)
+	target.SetFallback(release)
+
+	if _, overrun := killTarget(target, limit{}, nil); !overrun {
+		t.Error("stuck target did not overrun")
+	}
+	if target.State() != TargetStopped {
+		t.Errorf("fallback did not let the target stop, it is %v", target.State())
+	}
+}
+
+func TestOverrunExit(t *testing.T) {
+	target, release := stuckTarget("exit")
+	defer release()
+	target.SetDeadline(10*time.Millisecond, OverrunExit)
+
+	exited := false
+	killTarget(target, limit{}, func() { exited = true })
+	if !exited {
+		t.Error("exit was not called")
+	}
+}
+
+func TestDefaultDeadline(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.SetDefaultDeadline(10*time.Millisecond, OverrunSkip)
+	inherits, own := NewTarget("inherits"), NewTarget("own")
+	own.SetDeadline(time.Second, OverrunExit)
+	dex.Track(inherits)
+	dex.Track(own)
+
+	if lim := dex.limitFor(inherits); lim.deadline != 10*time.Millisecond || lim.source != "default" {
+		t.Errorf("inherited %+v", lim)
+	}
+	if lim := dex.limitFor(own); lim.deadline != time.Second || lim.policy != OverrunExit {
+		t.Errorf("override lost: %+v", lim)
+	}
+
+	stuck, release := stuckTarget("stuck")
+	defer release()
+	if _, overrun := killTarget(stuck, dex.limitFor(stuck), nil); !overrun {
+		t.Error("default deadline was not applied")
+	}
+}
+
+func TestCloserTimeout(t *testing.T) {
+	target := NewTarget("closer")
+	block := make(chan struct{})
+	defer close(block)
+	target.TrackCloserTimeout(closerFunc(func() error { <-block; return nil }), 10*time.Millisecond)
+
+	errs := target.kill()
+	if len(errs) != 1 {
+		t.Fatalf("expected a timeout, got %v", errs)
+	}
+	if _, ok := errs[0].(*CloseTimeoutError); !ok {
+		t.Errorf("unexpected error %v", errs[0])
+	}
+}
@@ -0,0 +1,97 @@
+package dexter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SendsTo hints that the target's goroutines send to channels, which may
+// be tracked by other targets.  When the target overruns its deadline the
+// hints are checked against the owners of the channels: a goroutine
+// blocked sending to a full channel of a target killed later keeps Wait
+// from ever returning, the timeout diagnostics name the suspected target
+// or cycle of targets.
+func (t *Target) SendsTo(channels ...interface{}) {
+	t.mu.Lock()
+	t.sendsTo = append(t.sendsTo, channels...)
+	t.mu.Unlock()
+}
+
+// BlockedSendError is reported for a target which overran while one of
+// the channels it sends to was full and owned by a target which hasn't
+// stopped.  Cycle lists the targets waiting on each other, starting and
+// ending with Target, when the owner in turn sends back to it.
+type BlockedSendError struct {
+	Target string
+	Owner  string
+	Cycle  []string
+}
+
+func (e *BlockedSendError) Error() string {
+	if len(e.Cycle) > 0 {
+		return fmt.Sprintf("dexter: suspected circular wait %s", strings.Join(e.Cycle, " -> "))
+	}
+	return fmt.Sprintf("dexter: target %s may be blocked sending to a full channel of target %s, "+
+		"which hasn't stopped", e.Target, e.Owner)
+}
+
+// blockedSends checks the send hints of target against the channels of
+// targets, see SendsTo
+func blockedSends(target *Target, targets []*Target) []error {
+	owners := map[interface{}]*Target{}
+	for _, t := range targets {
+		t.mu.Lock()
+		for _, ch := range t.channels {
+			owners[ch] = t
+		}
+		t.mu.Unlock()
+	}
+	sends := func(t *Target) []interface{} {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return append([]interface{}(nil), t.sendsTo...)
+	}
+
+	var errs []error
+	for _, ch := range sends(target) {
+		owner := owners[ch]
+		if owner == nil || owner == target || owner.State() == TargetStopped || !full(ch) {
+			continue
+		}
+		err := &BlockedSendError{Target: target.name, Owner: owner.name}
+		if path := sendPath(owner, target, owners, sends, map[*Target]bool{}); path != nil {
+			err.Cycle = append([]string{target.name}, path...)
+		}
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// sendPath returns the names of the targets from from to to following the
+// send hints, nil if there is no such path
+func sendPath(from, to *Target, owners map[interface{}]*Target, sends func(*Target) []interface{},
+	seen map[*Target]bool) []string {
+	if from == to {
+		return []string{to.name}
+	}
+	if seen[from] {
+		return nil
+	}
+	seen[from] = true
+	for _, ch := range sends(from) {
+		if next := owners[ch]; next != nil {
+			if path := sendPath(next, to, owners, sends, seen); path != nil {
+				return append([]string{from.name}, path...)
+			}
+		}
+	}
+	return nil
+}
+
+// full reports whether a send on ch would block for lack of a receiver
+// or buffer space
+func full(ch interface{}) bool {
+	v := reflect.ValueOf(ch)
+	return v.Kind() == reflect.Chan && v.Len() == v.Cap()
+}
@@ -0,0 +1,38 @@
+package dexter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlockedSends(t *testing.T) {
+	a, b := NewTarget("a"), NewTarget("b")
+	toA, toB := make(chan int), make(chan int)
+	a.TrackChannel(toA)
+	b.TrackChannel(toB)
+	a.SendsTo(toB)
+	b.SendsTo(toA)
+
+	release := make(chan struct{})
+	defer close(release)
+	a.Go(func() error {
+		select {
+		case toB <- 1:
+		case <-release:
+		}
+		return nil
+	})
+	a.SetDeadline(10*time.Millisecond, OverrunSkip)
+	if _, overrun := killTarget(a, limit{}, nil); !overrun {
+		t.Fatal("blocked target did not overrun")
+	}
+
+	errs := blockedSends(a, []*Target{a, b})
+	if len(errs) != 1 {
+		t.Fatalf("unexpected diagnostics %v", errs)
+	}
+	err := errs[0].(*BlockedSendError)
+	if err.Owner != "b" || len(err.Cycle) != 3 || err.Cycle[1] != "b" || err.Cycle[2] != "a" {
+		t.Errorf("unexpected diagnostic %v", err)
+	}
+}
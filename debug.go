@@ -0,0 +1,93 @@
+package dexter
+
+import (
+	"html/template"
+	"net/http"
+)
+
+// debugTarget is a row of the debug page's target table
+type debugTarget struct {
+	Name        string
+	Description string
+	Owner       string
+	Phase       Phase
+	State       TargetState
+	Stats       TargetStats
+}
+
+// debugPage is what the debug page template is rendered from
+type debugPage struct {
+	Serving bool
+	Targets []debugTarget
+	Current *Report
+	Last    *Report
+	History []Operation
+}
+
+var debugTemplate = template.Must(template.New("dexter").Parse(`<html>
+<head>
+<title>/debug/dexter/</title>
+{{if .Current}}<meta http-equiv="refresh" content="1">{{end}}
+<style>
+table { border-collapse: collapse; }
+td, th { padding: 2px 8px; text-align: left; }
+.overrun, .error { color: #c00; }
+</style>
+</head>
+<body>
+<h1>/debug/dexter/</h1>
+<p>Serving: {{.Serving}}</p>
+<h2>Targets</h2>
+<p>In kill order.</p>
+<table>
+<tr><th>Name</th><th>Owner</th><th>Phase</th><th>State</th><th>Funcs</th><th>Transactions</th><th>Closers</th><th>Channels</th><th>Pending</th></tr>
+{{range .Targets}}<tr><td>{{.Name}}{{with .Description}}<br><small>{{.}}</small>{{end}}</td><td>{{.Owner}}</td><td>{{.Phase}}</td><td>{{.State}}</td><td>{{.Stats.Funcs}}</td><td>{{.Stats.Transactions}}</td><td>{{.Stats.Closers}}</td><td>{{.Stats.Channels}}</td><td>{{.Stats.Pending}}</td></tr>
+{{end}}</table>
+{{with .Current}}<h2>Shutdown in progress</h2>
+{{template "report" .}}{{end}}
+{{with .Last}}<h2>Last shutdown</h2>
+{{template "report" .}}{{end}}
+{{with .History}}<h2>History</h2>
+<table>
+<tr><th>Operation</th><th>Started</th><th>Duration</th><th>Result</th></tr>
+{{range .}}<tr><td>{{.Kind}}</td><td>{{.Started.Format "2006-01-02 15:04:05.000"}}</td><td>{{.Duration}}</td><td>{{if .Err}}<span class="error">{{.Err}}</span>{{else if .Report}}{{if .Report.Clean}}clean{{else}}<span class="error">errors</span>{{end}}{{else}}ok{{end}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+{{define "report"}}<p>Reason: {{.Reason}}, plan: {{.Plan}}, started {{.Started.Format "2006-01-02 15:04:05.000"}}, took {{.Duration}}</p>
+{{range .Errors}}<p class="error">{{.}}</p>
+{{end}}<table>
+<tr><th>Target</th><th>Owner</th><th>Duration</th><th>Result</th><th>Details</th></tr>
+{{range .Targets}}<tr><td>{{.Name}}</td><td>{{.Owner}}</td><td>{{.Duration}}</td><td>{{if .Skipped}}skipped{{else if .Overrun}}<span class="overrun">overrun</span>{{else}}done{{end}}{{range .Errors}}<br><span class="error">{{.}}</span>{{end}}{{range .Running}}<br>{{.}} still running{{end}}</td><td>{{range $k, $v := .Details}}{{$k}}={{$v}} {{end}}</td></tr>
+{{end}}</table>
+{{end}}`))
+
+// DebugHandler serves an HTML page showing the tracked targets, their
+// resources and states, the shutdown in progress, the last shutdown
+// report and the history of recent operations.  It is meant to be mounted
+// at /debug/dexter/ next to net/http/pprof, the page refreshes itself
+// while shutting down.
+func (d *Dexter) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := debugPage{
+			Serving: d.Serving(),
+			Current: d.reportSoFar(),
+			Last:    d.LastReport(),
+			History: d.History(),
+		}
+		for _, target := range d.killOrder() {
+			page.Targets = append(page.Targets, debugTarget{
+				Name:        target.name,
+				Description: target.Description(),
+				Owner:       target.Owner(),
+				Phase:       target.Phase(),
+				State:       target.State(),
+				Stats:       target.Stats(),
+			})
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := debugTemplate.Execute(w, page); err != nil {
+			d.logf("Rendering debug page: %v\n", err)
+		}
+	})
+}
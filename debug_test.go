@@ -0,0 +1,44 @@
+package dexter
+
+import (
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDebugHandler(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.SetForceKillInterval(time.Minute)
+	target := NewTarget("<db>")
+	target.SetOwner("team-storage")
+	target.SetDescription("orders database")
+	target.TrackCloser(closerFunc(func() error { return nil }))
+	dex.Track(target)
+
+	render := func() string {
+		rec := httptest.NewRecorder()
+		dex.DebugHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/dexter/", nil))
+		return rec.Body.String()
+	}
+
+	page := render()
+	if !strings.Contains(page, "&lt;db&gt;") || !strings.Contains(page, "<td>1</td>") ||
+		!strings.Contains(page, "team-storage") || !strings.Contains(page, "orders database") {
+		t.Errorf("target missing from page:\n%s", page)
+	}
+	if strings.Contains(page, "Last shutdown") {
+		t.Error("last shutdown shown before any shutdown")
+	}
+
+	dex.SimulateSignal(syscall.SIGTERM)
+	dex.WaitAndKill()
+	page = render()
+	if !strings.Contains(page, "Last shutdown") || !strings.Contains(page, "stopped") || !strings.Contains(page, "History") {
+		t.Errorf("shutdown missing from page:\n%s", page)
+	}
+	if owner := dex.LastReport().Targets[0].Owner; owner != "team-storage" {
+		t.Errorf("report has owner %q", owner)
+	}
+}
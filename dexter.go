@@ -12,12 +12,6 @@
 //	import "os"
 //	import "github.com/ceocoder/dexter"
 //
-//	func foo(dex *dexter.Target, in <-chan string) {
-//		 for _ := range in {
-//
-//		 }
-//	}
-//
 //	func main() {
 //		dex := NewDexter()
 //
@@ -28,7 +22,9 @@
 //		f, err := os.Open("file.go")
 //		foo.TrackCloser(f)
 //
-//		go foo(foo, in)
+//		dexter.Consume(foo, in, func(s string) {
+//			// handle s
+//		})
 //
 //		bar := NewTarget("bar")
 //		out := make(chan int)
@@ -44,10 +40,12 @@
 package dexter
 
 import (
+	"context"
+	"io"
 	"log"
 	"os"
-	"os/signal"
-	"syscall"
+	"runtime/pprof"
+	"sync"
 	"time"
 )
 
@@ -63,60 +61,554 @@ func init() {
 // Dexter is a wrapper around sync.WaitGroup with convenience methods to intercept
 // SIGINT and SIGTERM and provides a way of graceful shutdown
 type Dexter struct {
+	mu              sync.Mutex
 	waiter          chan os.Signal
+	triggers        chan trigger
+	plans           []boundPlan
+	starts          []func() error
+	reloader        Reloader
+	cycle           sync.Mutex
 	targets         []*Target
+	tagOrder        []string
+	constraints     []orderConstraint
 	forceKillWindow time.Duration
+	noForceExit     bool
+	manual          bool
+	progress        io.Writer
+	defaultLimit    limit
 	exitFunc        func(int)
+	metrics         Metrics
+	profileDir      string
+	exitReportPath  string
+	lastRites       []func()
+	forceOnce       sync.Once
+	report          *Report
+	history         []Operation
+	durations       map[string][]time.Duration
+	durationsPath   string
+	current         *Report
+	gates           []gate
+	quiesced        bool
+	stopping        chan struct{}
+	stoppingOnce    sync.Once
+	closed          chan struct{}
+	closeOnce       sync.Once
+	closeErr        error
+	exitSignal      os.Signal
+	exitCode        int
+	exits           chan os.Signal
+	reloads         chan os.Signal
+	born            time.Time
+	minUptime       time.Duration
+	ready           chan struct{}
+	readyOnce       sync.Once
+	maxLifetime     time.Duration
+	exitCodes       map[string]int
+	signals         []os.Signal
+	shutdownDelay   time.Duration
+	pipeline        bool
+	finalizers      []finalizer
+	readinessPath   string
+	priorities      map[string]int
+	hookErrors      HookErrorPolicy
+	namePolicy      NamePolicy
+	forceKillMode   ForceKillMode
+	active          *trigger
+	shared          *sharedClosers
+	// muted is non zero while log lines are discarded, see showProgress
+	muted int32
 }
 
 // NewDexter returns a Dexter value.  One typically needs only single
-// copy per app.  By default it listens for SIGINT and SIGTERM.
+// copy per app.  By default it listens for SIGINT and SIGTERM, see
+// WithSignals.
 // When it receives either one - it will try to close all the io.Closer()s and
 // channels it is currently monitoring.
-func NewDexter() *Dexter {
+// On Windows it listens for os.Interrupt and the console events delivered as
+// SIGTERM, on Plan 9 for the interrupt note and under WebAssembly for nothing.
+// Only one Dexter may listen for signals at a time, NewDexter panics with a
+// *SignalOwnerError when another does, see WithManualTrigger and Child.
+func NewDexter(opts ...Option) *Dexter {
 	dex := &Dexter{
-		waiter:          make(chan os.Signal),
+		waiter:          make(chan os.Signal, 1),
+		triggers:        make(chan trigger, 1),
 		targets:         []*Target{},
 		forceKillWindow: 5 * time.Second,
 		exitFunc:        os.Exit,
+		metrics:         nopMetrics{},
+		stopping:        make(chan struct{}),
+		closed:          make(chan struct{}),
+		born:            time.Now(),
+		signals:         shutdownSignals,
+		shared:          newSharedClosers(),
+	}
+	for _, opt := range opts {
+		opt(dex)
+	}
+	if !dex.manual {
+		dex.ownSignals()
+	}
+	if dex.exits != nil {
+		go dex.watchExitSignal()
 	}
-	signal.Notify(dex.waiter, syscall.SIGINT, syscall.SIGTERM)
+	dex.startLifetime()
 	return dex
 }
 
 // SetForceKillInterval sets amount of time (in seconds) to wait before exiting with
 // non-zero return code, this helps one avoid stuck processes
 func (d *Dexter) SetForceKillInterval(interval time.Duration) {
+	d.mu.Lock()
 	d.forceKillWindow = interval
+	d.mu.Unlock()
+}
+
+// forceKillInterval returns the force kill window, it may be resized while
+// shutdown is running, e.g. by WatchPreemption
+func (d *Dexter) forceKillInterval() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.forceKillWindow
+}
+
+// SetMetrics sets the sink shutdown metrics are reported to, by default
+// metrics are discarded
+func (d *Dexter) SetMetrics(metrics Metrics) {
+	d.metrics = metrics
 }
 
 // Track adds a new target to Dexter's kill list,
 // this target will be killed in the order it was inserted in
+// It is shorthand for TrackPhase(PhaseWorkers, target)
 func (d *Dexter) Track(target *Target) {
-	d.targets = append(d.targets, target)
+	d.TrackPhase(PhaseWorkers, target)
+}
+
+// SimulateSignal delivers sig to WaitAndKill as if the OS had sent it,
+// without touching the process's signal handling.  It is meant for tests
+// and for platforms without signals.  If a signal is already pending sig
+// is dropped, shutdown starts either way.  The exit signal is delivered
+// like the OS would, see WithExitSignal.
+func (d *Dexter) SimulateSignal(sig os.Signal) {
+	if d.exits != nil && sig == d.exitSignal {
+		select {
+		case d.exits <- sig:
+		default:
+		}
+	}
+	select {
+	case d.waiter <- sig:
+	default:
+	}
+}
+
+// Stopping returns a channel which is closed as soon as shutdown starts,
+// before any target is killed.  Long running handlers can select on it.
+func (d *Dexter) Stopping() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stopping
 }
 
 // WaitAndKill for SIGINT or SIGTERM upon intercepting either one
 // * Close all closeable interfaces
 // * Close all monitored channels
+// Shutdown can also be started programmatically with Shutdown.
 func (d *Dexter) WaitAndKill() {
-	dlog.Println("Started Dexter - waiting for SIGINT or SIGTERM")
-	dlog.Printf("Received %v signal, shutting down\n", <-d.waiter)
-	dlog.Printf("Killing %d targets\n", len(d.targets))
+	d.WaitAndKillContext(context.Background())
+}
+
+// WaitAndKillContext is WaitAndKill bounded by ctx, for embedding dexter
+// under another orchestrator.  When ctx is done before shutdown started it
+// returns ctx.Err() without killing anything.  Once shutdown started, ctx's
+// deadline replaces the force kill window and ctx being done is handled
+// like the window expiring.  Signals are released however it returns.
+func (d *Dexter) WaitAndKillContext(ctx context.Context) error {
+	defer d.ReleaseSignals()
+	d.logf("Started Dexter - waiting for SIGINT or SIGTERM\n")
+	d.warnPlanBudget()
+	if err := d.Validate(); err != nil {
+		for _, problem := range err.(*ValidationError).Problems {
+			d.logf("Warning: %s\n", problem)
+		}
+	}
+	var trig trigger
+	select {
+	case sig := <-d.waiter:
+		trig = trigger{signal: sig, reason: sig.String()}
+		var ok bool
+		if trig, ok = d.holdEarly(ctx, trig); !ok {
+			return d.abandonWait(ctx)
+		}
+		d.logf("Received %v signal, shutting down\n", sig)
+	case trig = <-d.triggers:
+		d.logf("Shutdown requested: %s\n", trig.reason)
+	case <-d.closed:
+		return d.abandonWait(ctx)
+	case <-ctx.Done():
+		return d.abandonWait(ctx)
+	}
+	if !d.awaitReady(ctx, trig) {
+		return d.abandonWait(ctx)
+	}
+	d.shutdownContext(ctx, trig)
+
+	// stop loops
+	d.logf("Killed all targets returning control\n")
+	return nil
+}
+
+// abandonWait returns control without a shutdown, because d was closed or
+// ctx is done
+func (d *Dexter) abandonWait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		d.logf("Stopped waiting for a shutdown: %v\n", err)
+		return err
+	}
+	d.logf("Dexter was closed returning control\n")
+	return nil
+}
+
+// shutdown passes the gates and kills the targets of the plan selected by
+// trig, in order
+func (d *Dexter) shutdown(trig trigger) {
+	d.shutdownContext(context.Background(), trig)
+}
+
+// shutdownContext is shutdown with ctx's deadline as the force kill window,
+// ctx being done expires the window
+func (d *Dexter) shutdownContext(ctx context.Context, trig trigger) {
+	// a restart and a signal must not kill the same targets concurrently
+	d.cycle.Lock()
+	defer d.cycle.Unlock()
+	d.mu.Lock()
+	d.active = &trig
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		d.active = nil
+		d.mu.Unlock()
+	}()
+
+	releases, gateErrs := d.enterGates(ctx)
+	defer func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}()
+	d.mu.Lock()
+	d.stoppingOnce.Do(func() { close(d.stopping) })
+	d.mu.Unlock()
+	d.removeReadinessFile()
+	if d.shutdownDelay > 0 {
+		d.logf("Waiting %v before killing targets\n", d.shutdownDelay)
+		delay := time.NewTimer(d.shutdownDelay)
+		select {
+		case <-delay.C:
+		case <-ctx.Done():
+			delay.Stop()
+		}
+	}
+	plan := d.planFor(trig)
+	if policy := d.hookErrorPolicy(); len(gateErrs) > 0 && policy.Plan != nil {
+		d.logf("A gate failed, switching to the %s plan\n", policy.Plan.Name)
+		plan = *policy.Plan
+	}
+	targets := d.killOrder()
+	// without targets shutdown still reports, finalizes and honors the
+	// exit options, it just has nothing to kill
+	if len(targets) == 0 {
+		d.logf("No targets to kill with the %s plan\n", plan.Name)
+	} else {
+		d.logf("Killing %d targets with the %s plan\n", len(targets), plan.Name)
+	}
+	if eta := d.EstimatedDuration(); eta > 0 {
+		d.logf("Estimated shutdown time %v\n", eta.Round(time.Millisecond))
+	}
 
 	// starting a routine in the background to kill if process doesn't die
-	// gracefully in set time
-	timer := time.AfterFunc(d.forceKillWindow, func() {
-		dlog.Println("Timeout! - force exiting")
-		d.exitFunc(1)
+	// gracefully in set time, or to abandon the remaining targets when
+	// dexter must not exit
+	expired := make(chan struct{})
+	exit := d.forceKill
+	if d.noForceExit {
+		exit = nil
+	}
+	window, mode := d.forceKillInterval(), d.forceKillMode
+	if deadline, ok := ctx.Deadline(); ok {
+		window, mode = time.Until(deadline), ForceKillWholeShutdown
+	}
+	force := newForceTimer(window, func() {
+		if d.noForceExit {
+			labelled("", "force-abandon", d.forceAbandon)
+			close(expired)
+			return
+		}
+		labelled("", "force-kill", d.forceKill)
 	})
-	defer timer.Stop()
+	defer force.stop()
+	if mode == ForceKillWholeShutdown {
+		force.start()
+	}
+	if ctx.Done() != nil {
+		finished := make(chan struct{})
+		defer close(finished)
+		go func() {
+			select {
+			case <-ctx.Done():
+				force.fire()
+			case <-finished:
+			}
+		}()
+	}
 
-	for _, target := range d.targets {
-		target.kill()
-		target.Wait()
+	report := &Report{
+		Signal:  trig.signal,
+		Reason:  trig.reason,
+		Plan:    plan.Name,
+		Started: time.Now(),
+		Errors:  gateErrs,
+
+		Uptime:           time.Since(d.born),
+		EarlyTermination: trig.early,
+		ExitCode:         d.exitCodeFor(trig.reason),
+		Causes:           []string{trig.reason},
+
+		restart: trig.restart,
+	}
+	d.mu.Lock()
+	d.current = report
+	d.mu.Unlock()
+	if d.progress != nil && len(targets) > 0 {
+		stop := d.showProgress(d.progress, targets, force)
+		defer stop()
+	}
+	// a restart must leave signals to the WaitAndKill still waiting
+	if !trig.restart {
+		stop := d.collectCauses(trig, report, func(cause trigger) {
+			upgraded := d.planFor(cause)
+			d.logf("Switching to the %s plan for %s\n", upgraded.Name, cause.reason)
+			d.mu.Lock()
+			plan = upgraded
+			report.Plan = upgraded.Name
+			d.mu.Unlock()
+		})
+		defer stop()
+	}
+	skip := func(target *Target) bool {
+		d.mu.Lock()
+		plan := plan
+		d.mu.Unlock()
+		return (plan.Skip != nil && plan.Skip(target)) || !target.wanted()
+	}
+	if d.pipeline {
+		d.drainPipeline(targets, skip, exit)
 	}
+	yield := newYielder()
+	for _, target := range targets {
+		yield.step()
+		if skip(target) {
+			target.logf("Skipping target %s\n", target.name)
+			d.mu.Lock()
+			report.Targets = append(report.Targets, TargetReport{Name: target.name, Skipped: true})
+			d.mu.Unlock()
+			continue
+		}
 
-	// stop loops
-	dlog.Println("Killed all targets returning control")
+		targetStart := time.Now()
+		tag := "target:" + target.name
+		stopStall := func() {}
+		switch mode {
+		case ForceKillPerTarget:
+			force.restart()
+		case ForceKillAfterStall:
+			stopStall = d.watchStall(target, force)
+		}
+		errs, overrun := d.killBefore(target, exit, expired)
+		stopStall()
+		if overrun {
+			for _, err := range blockedSends(target, targets) {
+				d.logf("%v\n", err)
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) > 0 {
+			d.metrics.Count("closer.errors", int64(len(errs)), tag)
+		}
+		tr := TargetReport{
+			Name:     target.name,
+			Duration: time.Since(targetStart),
+			Errors:   errs,
+			Overrun:  overrun,
+			Details:  target.details(),
+
+			Description: target.Description(),
+			Owner:       target.Owner(),
+		}
+		d.mu.Lock()
+		report.Targets = append(report.Targets, tr)
+		d.mu.Unlock()
+		d.metrics.Timing("target.duration", time.Since(targetStart), tag)
+	}
+	d.reportRunning(report, targets)
+	if !trig.restart {
+		errs := d.finalize()
+		d.mu.Lock()
+		report.Errors = append(report.Errors, errs...)
+		d.mu.Unlock()
+	}
+	if policy := d.hookErrorPolicy(); len(report.Errors) > 0 && policy.ExitCode != 0 {
+		d.mu.Lock()
+		report.ExitCode = policy.ExitCode
+		d.mu.Unlock()
+	}
+	d.mu.Lock()
+	report.Duration = time.Since(report.Started)
+	d.report = report
+	d.current = nil
+	d.mu.Unlock()
+	d.record("shutdown", report.Started, nil, report)
+	d.recordDurations(report)
+	d.metrics.Timing("shutdown.duration", report.Duration)
+	d.writeExitReport(report, false)
+	for _, target := range targets {
+		// skipped and abandoned targets won't be killed by d anymore
+		target.unshare()
+		target.repanic()
+	}
+}
+
+// forceKill exits the process with a non-zero return code once the
+// force kill window has expired, only the first call does anything
+func (d *Dexter) forceKill() {
+	d.forceOnce.Do(func() {
+		d.alertf("Timeout! - force exiting\n")
+		d.metrics.Count("force_kill.triggered", 1)
+		if d.profileDir != "" {
+			captureProfiles(d.profileDir, profileCaptureTimeout)
+		}
+		logRunning(d.killOrder())
+		d.lastExit(1)
+	})
+}
+
+// lastExit releases locks, runs the last rites and exits with code
+func (d *Dexter) lastExit(code int) {
+	// a leaked lock would block the replacement process from starting
+	for _, target := range d.killOrder() {
+		target.releaseLocks()
+	}
+	// data files are synced within the last rites budget, first thing
+	rites := append([]func(){func() {
+		for _, target := range d.killOrder() {
+			target.syncFiles()
+		}
+		d.writeExitReport(d.reportSoFar(), true)
+	}}, d.lastRites...)
+	runLastRites(rites, lastRitesBudget)
+	d.exitFunc(code)
+}
+
+// killBefore kills target unless expired is closed first, in which case
+// the target is left to finish in the background and reported abandoned
+func (d *Dexter) killBefore(target *Target, exit func(), expired <-chan struct{}) ([]error, bool) {
+	type result struct {
+		errs    []error
+		overrun bool
+	}
+	done := make(chan result, 1)
+	go func() {
+		errs, overrun := killTarget(target, d.limitFor(target), exit)
+		done <- result{errs, overrun}
+	}()
+	select {
+	case r := <-done:
+		return r.errs, r.overrun
+	case <-expired:
+		target.logf("Abandoning target %s\n", target.label())
+		return []error{&ForcedAbandonError{Target: target.name, Window: d.forceKillInterval()}}, true
+	}
+}
+
+// killTarget shuts target down, giving up once it overruns its deadline
+// according to its policy, def applies if the target has no deadline of
+// its own.  exit is called for OverrunExit, when it is nil or returns the
+// target is abandoned like with OverrunSkip.
+func killTarget(target *Target, def limit, exit func()) (errs []error, overrun bool) {
+	defer func() { target.finishKill(errs, overrun) }()
+	lim := target.limit(def)
+	deadline, policy := lim.deadline, lim.policy
+	target.mu.Lock()
+	fallback := target.fallback
+	target.mu.Unlock()
+
+	ctx, cancel := withExit(context.Background(), exit), context.CancelFunc(func() {})
+	if deadline > 0 {
+		ctx, cancel = context.WithTimeout(ctx, deadline)
+	}
+	defer cancel()
+	done := make(chan []error, 1)
+	go func() {
+		done <- shutdownTarget(ctx, target)
+	}()
+	if deadline <= 0 || policy == OverrunWait {
+		return <-done, false
+	}
+
+	select {
+	case errs := <-done:
+		return errs, false
+	case <-time.After(deadline):
+	}
+	target.logf("Target %s overran its %v deadline, policy %v\n", target.label(), deadline, policy)
+
+	switch policy {
+	case OverrunFallback:
+		if fallback != nil {
+			labelled(target.name, "fallback", fallback)
+		}
+		select {
+		case errs := <-done:
+			return errs, true
+		case <-time.After(deadline):
+		}
+	case OverrunExit:
+		if exit != nil {
+			exit()
+		}
+	}
+	target.logf("Abandoning target %s\n", target.label())
+	return nil, true
+}
+
+// shutdownTarget closes the target's resources and waits for it to drain,
+// both steps run on their own goroutines labelled with the target's name.
+// ctx is handed to closers implementing ContextCloser.
+func shutdownTarget(ctx context.Context, target *Target) []error {
+	closed := make(chan []error, 1)
+	go labelled(target.name, "close", func() {
+		closed <- target.killContext(ctx)
+	})
+	errs := <-closed
+
+	drained := make(chan struct{})
+	go labelled(target.name, "drain", func() {
+		target.Wait()
+		errs = append(errs, target.goErrors()...)
+		errs = append(errs, target.releaseLocks()...)
+		target.stopped()
+		close(drained)
+	})
+	<-drained
+	return errs
+}
+
+// labelled runs fn with runtime/pprof labels attached to the calling goroutine
+// so CPU and goroutine profiles taken during a slow shutdown show which
+// target the time was spent on
+func labelled(target, role string, fn func()) {
+	labels := pprof.Labels("dexter.target", target, "dexter.role", role)
+	pprof.Do(context.Background(), labels, func(context.Context) {
+		fn()
+	})
 }
@@ -1,9 +1,11 @@
 package dexter
 
 import (
+	"bytes"
 	"os"
-	"os/signal"
-	"syscall"
+	"path/filepath"
+	"runtime/pprof"
+	"strings"
 	"testing"
 	"time"
 )
@@ -54,7 +56,7 @@ func TestDexter(t *testing.T) {
 	go f2(stage2, c2In)
 	go f3(stage3, c3In)
 
-	dex := NewDexter()
+	dex := NewDexter(WithManualTrigger())
 	dex.Track(stage1)
 	dex.Track(stage2)
 	dex.Track(stage3)
@@ -62,7 +64,7 @@ func TestDexter(t *testing.T) {
 	go func() {
 		// kill after it waiting for small amount of time
 		time.Sleep(10 * time.Millisecond)
-		syscall.Kill(os.Getpid(), syscall.SIGINT)
+		dex.SimulateSignal(os.Interrupt)
 	}()
 	dex.WaitAndKill()
 }
@@ -88,19 +90,15 @@ func TestForceKillInterval(t *testing.T) {
 		}
 	}
 
-	dex := &Dexter{
-		waiter:          make(chan os.Signal),
-		targets:         []*Target{},
-		forceKillWindow: 1 * time.Second,
-		exitFunc:        dummyExitFunc,
-	}
-	signal.Notify(dex.waiter, syscall.SIGINT, syscall.SIGTERM)
+	dex := NewDexter(WithManualTrigger())
+	dex.SetForceKillInterval(1 * time.Second)
+	dex.exitFunc = dummyExitFunc
 	dex.Track(stage1Stuck)
 
 	go func() {
 		// kill after it waiting for small amount of time
 		time.Sleep(10 * time.Millisecond)
-		syscall.Kill(os.Getpid(), syscall.SIGINT)
+		dex.SimulateSignal(os.Interrupt)
 	}()
 
 	go dex.WaitAndKill()
@@ -114,3 +112,67 @@ func TestForceKillInterval(t *testing.T) {
 	}
 
 }
+
+func TestKillTargetLabels(t *testing.T) {
+	target := NewTarget("labelled")
+	target.Add(1)
+
+	seen := make(chan bool, 1)
+	target.TrackCloser(closerFunc(func() error {
+		var buf bytes.Buffer
+		pprof.Lookup("goroutine").WriteTo(&buf, 1)
+		seen <- strings.Contains(buf.String(), `"dexter.target":"labelled"`)
+		target.Done()
+		return nil
+	}))
+
+	target.Kill()
+	if !<-seen {
+		t.Error("close worker is missing its pprof labels")
+	}
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func TestShutdownWithoutTargets(t *testing.T) {
+	dir := t.TempDir()
+	report, ready := filepath.Join(dir, "exit.json"), filepath.Join(dir, "ready")
+	dex := NewDexter(WithManualTrigger(), WithExitReport(report), WithReadinessFile(ready))
+	dex.SetExitCode("drain", 3)
+	finalized := false
+	dex.Finalize("pool", func() error {
+		finalized = true
+		return nil
+	})
+	dex.Ready()
+
+	dex.Shutdown("drain")
+	dex.WaitAndKill()
+	last := dex.LastReport()
+	if last == nil || last.Reason != "drain" || last.ExitCode != 3 || len(last.Targets) != 0 {
+		t.Fatalf("got report %+v", last)
+	}
+	if dex.ExitCode() != 3 || !finalized || len(dex.History()) != 1 {
+		t.Errorf("exit code %d, finalized %v, history %v", dex.ExitCode(), finalized, dex.History())
+	}
+	if _, err := os.Stat(report); err != nil {
+		t.Errorf("exit report not written: %v", err)
+	}
+	if _, err := os.Stat(ready); !os.IsNotExist(err) {
+		t.Errorf("readiness file left: %v", err)
+	}
+}
+
+func TestShutdownIdleTarget(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.Track(NewTarget("unused"))
+
+	dex.Close()
+	if report := dex.LastReport(); len(report.Targets) != 1 || !report.Clean() {
+		t.Errorf("got report %+v", report)
+	}
+}
@@ -0,0 +1,119 @@
+// Package dextertest provides utilities for testing code which wires its
+// resources into a dexter.Target.
+//
+// Usage example:
+//
+//	func TestServerShutdown(t *testing.T) {
+//		rec := dextertest.NewRecordingTarget("server")
+//		srv := NewServer(rec.Target)
+//
+//		rec.Kill()
+//		rec.AssertReleased(t, srv.listener, srv.requests)
+//		rec.AssertOrder(t, srv.listener, srv.requests)
+//		rec.AssertNoErrors(t)
+//	}
+package dextertest
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ceocoder/dexter"
+)
+
+// RecordingTarget is a dexter.Target which records every resource it
+// releases, in order and with the time it happened
+type RecordingTarget struct {
+	*dexter.Target
+
+	mu       sync.Mutex
+	releases []dexter.Release
+}
+
+// NewRecordingTarget returns a recording target, hand its Target to the code
+// under test and call Kill to run the target's shutdown
+func NewRecordingTarget(name string) *RecordingTarget {
+	rec := &RecordingTarget{Target: dexter.NewTarget(name)}
+	rec.OnRelease(func(r dexter.Release) {
+		rec.mu.Lock()
+		rec.releases = append(rec.releases, r)
+		rec.mu.Unlock()
+	})
+	return rec
+}
+
+// Releases returns everything the target released so far, in order
+func (r *RecordingTarget) Releases() []dexter.Release {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]dexter.Release(nil), r.releases...)
+}
+
+// Released reports whether resource has been released, and when
+func (r *RecordingTarget) Released(resource interface{}) (dexter.Release, bool) {
+	i := r.index(resource)
+	if i < 0 {
+		return dexter.Release{}, false
+	}
+	return r.Releases()[i], true
+}
+
+// AssertReleased fails the test for every resource which wasn't released
+func (r *RecordingTarget) AssertReleased(t testing.TB, resources ...interface{}) {
+	t.Helper()
+	for _, resource := range resources {
+		if r.index(resource) < 0 {
+			t.Errorf("target %s did not release %T %v", r.Name(), resource, resource)
+		}
+	}
+}
+
+// AssertOrder fails the test unless resources were all released, in the
+// order given
+func (r *RecordingTarget) AssertOrder(t testing.TB, resources ...interface{}) {
+	t.Helper()
+	last := -1
+	for _, resource := range resources {
+		i := r.index(resource)
+		if i < 0 {
+			t.Errorf("target %s did not release %T %v", r.Name(), resource, resource)
+			return
+		}
+		if i < last {
+			t.Errorf("target %s released %T %v out of order", r.Name(), resource, resource)
+			return
+		}
+		last = i
+	}
+}
+
+// AssertNoErrors fails the test for every release which returned an error
+func (r *RecordingTarget) AssertNoErrors(t testing.TB) {
+	t.Helper()
+	for _, release := range r.Releases() {
+		if release.Err != nil {
+			t.Errorf("target %s failed to release %T: %v", r.Name(), release.Resource, release.Err)
+		}
+	}
+}
+
+func (r *RecordingTarget) index(resource interface{}) int {
+	for i, release := range r.Releases() {
+		if same(release.Resource, resource) {
+			return i
+		}
+	}
+	return -1
+}
+
+// same compares resources without panicking on uncomparable types
+func same(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
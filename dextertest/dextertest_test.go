@@ -0,0 +1,96 @@
+package dextertest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ceocoder/dexter"
+)
+
+type closer struct {
+	err error
+}
+
+func (c *closer) Close() error {
+	return c.err
+}
+
+func TestRecordingTarget(t *testing.T) {
+	rec := NewRecordingTarget("wired")
+	conn := &closer{}
+	ch := make(chan int)
+	rec.TrackCloser(conn)
+	rec.TrackChannel(ch)
+
+	rec.Kill()
+
+	rec.AssertReleased(t, conn, ch)
+	rec.AssertOrder(t, conn, ch)
+	rec.AssertNoErrors(t)
+	if _, ok := rec.Released(&closer{}); ok {
+		t.Error("untracked closer reported as released")
+	}
+}
+
+func TestRecordingTargetErrors(t *testing.T) {
+	rec := NewRecordingTarget("broken")
+	broken := &closer{err: errors.New("boom")}
+	rec.TrackCloser(broken)
+	rec.Kill()
+
+	release, ok := rec.Released(broken)
+	if !ok || release.Err == nil || release.At.IsZero() {
+		t.Errorf("unexpected release %+v", release)
+	}
+}
+
+// recorder captures the failures of a strict check
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestStrict(t *testing.T) {
+	dex := Strict(t)
+	target := dexter.NewTarget("clean")
+	in := make(chan int)
+	target.TrackChannel(in)
+	target.Go(func() error {
+		for range in {
+		}
+		return nil
+	})
+	dex.Track(target)
+	dex.Kill()
+}
+
+func TestStrictFailures(t *testing.T) {
+	rec := &recorder{TB: t}
+	dex := Strict(rec)
+	dex.SetForceKillInterval(20 * time.Millisecond)
+	broken := dexter.NewTarget("broken")
+	broken.TrackCloser(&closer{err: errors.New("boom")})
+	leaky := dexter.NewTarget("leaky")
+	stop := make(chan struct{})
+	defer close(stop)
+	leaky.Go(func() error {
+		<-stop
+		return nil
+	})
+	dex.Track(broken)
+	dex.Track(leaky)
+	dex.Kill()
+
+	// the closer error, the overrun, the abandonment and the goroutine
+	if len(rec.failures) != 4 {
+		t.Errorf("unexpected failures %q", rec.failures)
+	}
+}
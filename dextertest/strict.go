@@ -0,0 +1,72 @@
+package dextertest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ceocoder/dexter"
+)
+
+// StrictDexter is a dexter.Dexter whose shutdown is checked for
+// completeness, see Strict
+type StrictDexter struct {
+	*dexter.Dexter
+
+	// MaxDuration fails the test when shutdown takes longer, zero means
+	// there is no limit
+	MaxDuration time.Duration
+
+	t testing.TB
+}
+
+// Strict returns a Dexter for tests which fails t unless every tracked
+// target is killed in full: no target skipped or overrunning its deadline,
+// no closer failing, no goroutine started with Target.Go surviving and,
+// if MaxDuration is set, the shutdown finishing in time.  It never exits
+// the test binary, a stuck shutdown is abandoned after the force kill
+// window and reported, and it doesn't listen for OS signals.
+//
+//	func TestShutdown(t *testing.T) {
+//		dex := dextertest.Strict(t)
+//		dex.MaxDuration = time.Second
+//		dex.Track(NewServer().Target)
+//		dex.Kill()
+//	}
+func Strict(t testing.TB) *StrictDexter {
+	return &StrictDexter{
+		Dexter: dexter.NewDexter(dexter.WithManualTrigger(), dexter.WithNoForceExit()),
+		t:      t,
+	}
+}
+
+// Kill shuts every tracked target down and checks the shutdown
+func (s *StrictDexter) Kill() {
+	s.t.Helper()
+	targets := s.Targets()
+	s.Shutdown("dextertest")
+	s.WaitAndKill()
+
+	report := s.LastReport()
+	if s.MaxDuration > 0 && report.Duration > s.MaxDuration {
+		s.t.Errorf("shutdown took %v, more than %v", report.Duration, s.MaxDuration)
+	}
+	for _, err := range report.Errors {
+		s.t.Errorf("shutdown: %v", err)
+	}
+	for _, tr := range report.Targets {
+		if tr.Skipped {
+			s.t.Errorf("target %s was not killed", tr.Name)
+		}
+		if tr.Overrun {
+			s.t.Errorf("target %s overran its deadline", tr.Name)
+		}
+		for _, err := range tr.Errors {
+			s.t.Errorf("target %s: %v", tr.Name, err)
+		}
+	}
+	for _, target := range targets {
+		if n := target.Stats().Goroutines; n > 0 {
+			s.t.Errorf("target %s has %d goroutines still running", target.Name(), n)
+		}
+	}
+}
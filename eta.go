@@ -0,0 +1,111 @@
+package dexter
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+// durationSamples is how many recent shutdown durations are kept per target
+const durationSamples = 5
+
+// WithDurationHistory persists the shutdown duration of every target to the
+// JSON file at path, so estimates survive restarts.  Durations are always
+// recorded in memory, see EstimatedDuration.
+func WithDurationHistory(path string) Option {
+	return func(d *Dexter) {
+		d.durationsPath = path
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				dlog.Printf("Reading duration history: %v\n", err)
+			}
+			return
+		}
+		if err := json.Unmarshal(data, &d.durations); err != nil {
+			dlog.Printf("Decoding duration history: %v\n", err)
+		}
+	}
+}
+
+// EstimatedDuration estimates how long killing every tracked target takes
+// from their recent shutdowns, targets which never shut down count as zero
+func (d *Dexter) EstimatedDuration() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var eta time.Duration
+	for _, target := range d.targets {
+		eta += d.estimate(target.name)
+	}
+	return eta
+}
+
+// estimate is the mean of the recorded durations of the target called
+// name, d.mu must be held
+func (d *Dexter) estimate(name string) time.Duration {
+	samples := d.durations[name]
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, sample := range samples {
+		sum += sample
+	}
+	return sum / time.Duration(len(samples))
+}
+
+// warnOverBudget warns when tracking targets, in place of replaced unless
+// it is nil, makes the estimated shutdown exceed the force kill window.
+// Every way of tracking a target calls it once the targets are tracked.
+func (d *Dexter) warnOverBudget(replaced *Target, targets ...*Target) {
+	eta := d.EstimatedDuration()
+	d.mu.Lock()
+	before, window := eta, d.forceKillWindow
+	var names []string
+	for _, target := range targets {
+		before -= d.estimate(target.name)
+		names = append(names, target.name)
+	}
+	if replaced != nil {
+		before += d.estimate(replaced.name)
+	}
+	d.mu.Unlock()
+	if eta > window && before <= window {
+		d.logf("Warning: tracking %s makes the estimated shutdown %v, longer than the %v force kill window\n",
+			strings.Join(names, ", "), eta.Round(time.Millisecond), window)
+	}
+}
+
+// recordDurations adds the durations of report's targets to the history and
+// persists it
+func (d *Dexter) recordDurations(report *Report) {
+	d.mu.Lock()
+	if d.durations == nil {
+		d.durations = map[string][]time.Duration{}
+	}
+	for _, tr := range report.Targets {
+		if tr.Skipped || tr.Overrun {
+			continue
+		}
+		samples := append(d.durations[tr.Name], tr.Duration)
+		if len(samples) > durationSamples {
+			samples = samples[len(samples)-durationSamples:]
+		}
+		d.durations[tr.Name] = samples
+	}
+	path := d.durationsPath
+	data, err := json.Marshal(d.durations)
+	d.mu.Unlock()
+
+	if path == "" {
+		return
+	}
+	if err == nil {
+		err = writeFileAtomic(path, data)
+	}
+	if err != nil {
+		d.logf("Writing duration history: %v\n", err)
+	}
+}
@@ -0,0 +1,75 @@
+package dexter
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDurationHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dexter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "durations.json")
+
+	dex := NewDexter(WithManualTrigger(), WithDurationHistory(path))
+	slow := NewTarget("slow")
+	slow.TrackCloser(closerFunc(func() error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}))
+	dex.Track(slow)
+	dex.Shutdown("test")
+	dex.WaitAndKill()
+
+	next := NewDexter(WithManualTrigger(), WithDurationHistory(path))
+	next.SetForceKillInterval(10 * time.Millisecond)
+	var buf bytes.Buffer
+	dlog.SetOutput(&buf)
+	next.Track(NewTarget("slow"))
+	dlog.SetOutput(os.Stdout)
+
+	if eta := next.EstimatedDuration(); eta < 20*time.Millisecond {
+		t.Errorf("estimate %v not loaded from the history file", eta)
+	}
+	if !strings.Contains(buf.String(), "longer than the 10ms force kill window") {
+		t.Errorf("no warning when tracking over budget: %q", buf.String())
+	}
+}
+
+func TestOverBudgetWarningWithoutPhase(t *testing.T) {
+	var buf bytes.Buffer
+	dlog.SetOutput(&buf)
+	defer dlog.SetOutput(os.Stdout)
+	history := map[string][]time.Duration{"slow": {20 * time.Millisecond}}
+
+	dex := NewDexter(WithManualTrigger())
+	dex.durations = history
+	dex.SetForceKillInterval(10 * time.Millisecond)
+	dex.Track(NewTarget("db"))
+	if err := dex.TrackAfter("db", NewTarget("slow")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "tracking slow makes the estimated shutdown 20ms") {
+		t.Errorf("no warning when tracking after a target: %q", buf.String())
+	}
+
+	buf.Reset()
+	other := NewDexter(WithManualTrigger())
+	other.Track(NewTarget("slow"))
+	adopter := NewDexter(WithManualTrigger())
+	adopter.durations = history
+	adopter.SetForceKillInterval(10 * time.Millisecond)
+	if err := adopter.Adopt(other); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "tracking slow makes the estimated shutdown 20ms") {
+		t.Errorf("no warning when adopting: %q", buf.String())
+	}
+}
@@ -0,0 +1,148 @@
+package dexter
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WithExitReport makes dexter write a JSON exit report to path whenever
+// the process terminates through it, so supervisors and preStop hooks can
+// tell clean drains from timeouts without parsing logs.  The report is
+// written after every shutdown and, marked forced, right before a forced
+// exit.  The file is replaced atomically.
+func WithExitReport(path string) Option {
+	return func(d *Dexter) {
+		d.exitReportPath = path
+	}
+}
+
+// exitReport is the JSON document written by WithExitReport
+type exitReport struct {
+	Reason     string             `json:"reason"`
+	Causes     []string           `json:"causes,omitempty"`
+	Plan       string             `json:"plan"`
+	Clean      bool               `json:"clean"`
+	Forced     bool               `json:"forced"`
+	Started    time.Time          `json:"started"`
+	DurationMS int64              `json:"duration_ms"`
+	UptimeMS   int64              `json:"uptime_ms"`
+	Early      bool               `json:"early_termination,omitempty"`
+	ExitCode   int                `json:"exit_code"`
+	Errors     []string           `json:"errors,omitempty"`
+	Targets    []exitTargetReport `json:"targets"`
+}
+
+// exitTargetReport is the status of a single target in an exit report,
+// Status is one of done, failed, overrun, skipped or pending
+type exitTargetReport struct {
+	Name       string   `json:"name"`
+	Owner      string   `json:"owner,omitempty"`
+	Status     string   `json:"status"`
+	DurationMS int64    `json:"duration_ms"`
+	Errors     []string `json:"errors,omitempty"`
+	Running    []string `json:"running,omitempty"`
+}
+
+// newExitReport converts report, the pending targets were not reached
+// before a forced exit
+func newExitReport(report *Report, pending []TargetReport, forced bool) exitReport {
+	exit := exitReport{
+		Reason:     report.Reason,
+		Causes:     report.Causes,
+		Plan:       report.Plan,
+		Clean:      report.Clean() && !forced,
+		Forced:     forced,
+		Started:    report.Started,
+		DurationMS: int64(report.Duration / time.Millisecond),
+		UptimeMS:   int64(report.Uptime / time.Millisecond),
+		Early:      report.EarlyTermination,
+		ExitCode:   report.ExitCode,
+		Errors:     errorStrings(report.Errors),
+		Targets:    []exitTargetReport{},
+	}
+	for _, tr := range report.Targets {
+		status := "done"
+		switch {
+		case tr.Skipped:
+			status = "skipped"
+		case tr.Overrun:
+			status = "overrun"
+		case len(tr.Errors) > 0:
+			status = "failed"
+		}
+		exit.Targets = append(exit.Targets, exitTargetReport{
+			Name:       tr.Name,
+			Owner:      tr.Owner,
+			Status:     status,
+			DurationMS: int64(tr.Duration / time.Millisecond),
+			Errors:     errorStrings(tr.Errors),
+			Running:    tr.Running,
+		})
+	}
+	for _, tr := range pending {
+		exit.Targets = append(exit.Targets, exitTargetReport{Name: tr.Name, Status: "pending", Running: tr.Running})
+	}
+	return exit
+}
+
+func errorStrings(errs []error) []string {
+	var out []string
+	for _, err := range errs {
+		out = append(out, err.Error())
+	}
+	return out
+}
+
+// writeExitReport writes report to the exit report path, if one is set
+func (d *Dexter) writeExitReport(report *Report, forced bool) {
+	if d.exitReportPath == "" || report == nil {
+		return
+	}
+	var pending []TargetReport
+	if forced {
+		// a forced exit is the last chance to tell which goroutines hung
+		targets := map[string]*Target{}
+		for _, target := range d.killOrder() {
+			targets[target.name] = target
+		}
+		for i, tr := range report.Targets {
+			if target := targets[tr.Name]; target != nil && !tr.Skipped {
+				report.Targets[i].Running = target.Running()
+			}
+			delete(targets, tr.Name)
+		}
+		for _, target := range d.killOrder() {
+			if targets[target.name] != nil {
+				pending = append(pending, TargetReport{Name: target.name, Running: target.Running()})
+			}
+		}
+	}
+	data, err := json.MarshalIndent(newExitReport(report, pending, forced), "", "  ")
+	if err != nil {
+		d.logf("Encoding exit report: %v\n", err)
+		return
+	}
+	if err := writeFileAtomic(d.exitReportPath, append(data, '\n')); err != nil {
+		d.logf("Writing exit report: %v\n", err)
+	}
+}
+
+// writeFileAtomic replaces path with data, readers never see a partial file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,75 @@
+package dexter
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func readExitReport(t *testing.T, path string) exitReport {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report exitReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestExitReportClean(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dexter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "exit.json")
+
+	dex := NewDexter(WithManualTrigger(), WithExitReport(path))
+	dex.Track(NewTarget("db"))
+	dex.SimulateSignal(syscall.SIGTERM)
+	dex.WaitAndKill()
+
+	report := readExitReport(t, path)
+	if !report.Clean || report.Forced || report.Reason != "terminated" {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if len(report.Targets) != 1 || report.Targets[0].Status != "done" {
+		t.Errorf("unexpected targets %+v", report.Targets)
+	}
+}
+
+func TestExitReportForced(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dexter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "exit.json")
+
+	dex := NewDexter(WithManualTrigger(), WithExitReport(path))
+	dex.SetForceKillInterval(20 * time.Millisecond)
+	exited := make(chan struct{})
+	dex.exitFunc = func(int) { close(exited) }
+	stuck, release := stuckTarget("stuck")
+	defer release()
+	dex.Track(stuck)
+	dex.Track(NewTarget("after"))
+
+	go dex.WaitAndKill()
+	dex.Shutdown("deploy")
+	<-exited
+
+	report := readExitReport(t, path)
+	if report.Clean || !report.Forced || report.Reason != "deploy" {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if len(report.Targets) != 2 || report.Targets[0].Status != "pending" || report.Targets[1].Name != "after" {
+		t.Errorf("unexpected targets %+v", report.Targets)
+	}
+}
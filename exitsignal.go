@@ -0,0 +1,50 @@
+package dexter
+
+import (
+	"os"
+	"os/signal"
+)
+
+// WithExitSignal makes sig skip the graceful shutdown and exit the process
+// right away with code, after releasing locks and running the last rites
+// like a force kill does.  If sig is also a shutdown signal, e.g. SIGTERM,
+// the first one starts the graceful shutdown and the second one exits.
+func WithExitSignal(sig os.Signal, code int) Option {
+	return func(d *Dexter) {
+		d.exitSignal, d.exitCode = sig, code
+		d.exits = make(chan os.Signal, 1)
+	}
+}
+
+// watchExitSignal exits once the exit signal arrives
+func (d *Dexter) watchExitSignal() {
+	graceful := false
+	for _, sig := range d.signals {
+		graceful = graceful || sig == d.exitSignal
+	}
+	for sig := range d.exits {
+		if graceful {
+			d.logf("Received %v signal, send it again to exit immediately\n", sig)
+			graceful = false
+			continue
+		}
+		d.exitNow(sig)
+	}
+}
+
+// exitNow exits with the exit signal's code unless the process is already
+// being force killed
+func (d *Dexter) exitNow(sig os.Signal) {
+	d.forceOnce.Do(func() {
+		d.logf("Received %v signal, exiting immediately\n", sig)
+		d.metrics.Count("exit_signal.received", 1)
+		d.lastExit(d.exitCode)
+	})
+}
+
+// notifyExitSignal relays the exit signal, d must own the OS signals
+func (d *Dexter) notifyExitSignal() {
+	if d.exits != nil {
+		signal.Notify(d.exits, d.exitSignal)
+	}
+}
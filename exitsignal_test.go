@@ -0,0 +1,44 @@
+package dexter
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestExitSignal(t *testing.T) {
+	quit := testSignal("quit")
+	var rites []string
+	dex := NewDexter(WithManualTrigger(), WithExitSignal(quit, 3), WithLastRites(func() {
+		rites = append(rites, "pidfile")
+	}))
+	exited := make(chan int, 1)
+	dex.exitFunc = func(code int) { exited <- code }
+
+	dex.SimulateSignal(quit)
+	select {
+	case code := <-exited:
+		if code != 3 || len(rites) != 1 {
+			t.Errorf("exited with %d after rites %v", code, rites)
+		}
+	case <-time.After(time.Second):
+		t.Error("exit signal did not exit")
+	}
+}
+
+func TestRepeatedExitSignal(t *testing.T) {
+	dex := NewDexter(WithManualTrigger(), WithExitSignal(os.Interrupt, 130))
+	exited := make(chan int, 1)
+	dex.exitFunc = func(code int) { exited <- code }
+
+	dex.exits <- os.Interrupt
+	select {
+	case <-exited:
+		t.Fatal("first interrupt exited")
+	case <-time.After(20 * time.Millisecond):
+	}
+	dex.exits <- os.Interrupt
+	if code := <-exited; code != 130 {
+		t.Errorf("exited with %d", code)
+	}
+}
@@ -0,0 +1,70 @@
+package dexter
+
+import (
+	"os"
+	"sync"
+)
+
+// syncedFile is an *os.File which is synced before it is closed
+type syncedFile struct {
+	f *os.File
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// TrackFileSync is TrackCloser for data files, f is synced to disk before it
+// is closed.  If the process is force killed first f is still synced right
+// before exiting, so what was written during the run is durable even after
+// an unclean exit.
+func (t *Target) TrackFileSync(f *os.File) {
+	file := &syncedFile{f: f}
+	t.mu.Lock()
+	t.synced = append(t.synced, file)
+	t.mu.Unlock()
+	t.TrackCloser(file)
+}
+
+func (s *syncedFile) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	err := s.f.Sync()
+	if cerr := s.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// sync syncs the file unless it has already been closed
+func (s *syncedFile) sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	return s.f.Sync()
+}
+
+func (s *syncedFile) unwrap() interface{} {
+	return s.f
+}
+
+// syncFiles syncs every file tracked with TrackFileSync which is still
+// open, including those of adopted targets
+func (t *Target) syncFiles() {
+	t.mu.Lock()
+	files, adopted := t.synced, t.adopted
+	t.mu.Unlock()
+	for _, file := range files {
+		if err := file.sync(); err != nil {
+			t.logf("Error syncing %s in target %s: %v\n", file.f.Name(), t.name, err)
+		}
+	}
+	for _, other := range adopted {
+		other.syncFiles()
+	}
+}
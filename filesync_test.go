@@ -0,0 +1,34 @@
+package dexter
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestTrackFileSync(t *testing.T) {
+	f, err := ioutil.TempFile("", "dexter-sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	var released interface{}
+	target := NewTarget("journal")
+	target.TrackFileSync(f)
+	target.OnRelease(func(r Release) { released = r.Resource })
+
+	// syncing from the force kill path leaves the file open
+	target.syncFiles()
+	if _, err := f.Write([]byte("x")); err != nil {
+		t.Fatal(err)
+	}
+
+	target.Kill()
+	if released != f {
+		t.Errorf("release hook saw %v instead of the file", released)
+	}
+	if err := f.Close(); err == nil {
+		t.Error("file was not closed")
+	}
+}
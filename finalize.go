@@ -0,0 +1,39 @@
+package dexter
+
+import "fmt"
+
+// finalizer is a cleanup func registered with Finalize
+type finalizer struct {
+	name string
+	fn   func() error
+}
+
+// Finalize registers fn to run once every target has been killed, in a
+// late phase of its own.  It is meant for releasing what the targets may
+// still be using until the very end, such as pooled buffers, mmapped files
+// or cgo allocations, without entangling them with the targets' ordering.
+// Finalizers run in the reverse order they were registered, after the
+// last shutdown only: Restart keeps them.  Their errors are recorded in
+// the report.
+func (d *Dexter) Finalize(name string, fn func() error) {
+	d.mu.Lock()
+	d.finalizers = append(d.finalizers, finalizer{name: name, fn: fn})
+	d.mu.Unlock()
+}
+
+// finalize runs the finalizers and returns their errors
+func (d *Dexter) finalize() (errs []error) {
+	d.mu.Lock()
+	finalizers := d.finalizers
+	d.finalizers = nil
+	d.mu.Unlock()
+
+	for i := len(finalizers) - 1; i >= 0; i-- {
+		f := finalizers[i]
+		d.logf("Running finalizer %s\n", f.name)
+		if err := f.fn(); err != nil {
+			errs = append(errs, fmt.Errorf("finalizer %s: %w", f.name, err))
+		}
+	}
+	return errs
+}
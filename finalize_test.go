@@ -0,0 +1,44 @@
+package dexter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFinalize(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	var order []string
+	target := NewTarget("db")
+	target.TrackCloser(closerFunc(func() error {
+		order = append(order, "db")
+		return nil
+	}))
+	dex.Track(target)
+	dex.Finalize("buffers", func() error {
+		order = append(order, "buffers")
+		return nil
+	})
+	dex.Finalize("mmap", func() error {
+		order = append(order, "mmap")
+		return errors.New("munmap failed")
+	})
+
+	if err := dex.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"db"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("restart ran %v, want %v", order, want)
+	}
+
+	order = nil
+	dex.Track(target)
+	dex.Close()
+	if want := []string{"mmap", "buffers"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("ran %v, want %v", order, want)
+	}
+	report := dex.LastReport()
+	if len(report.Errors) != 1 || report.Clean() {
+		t.Errorf("report errors %v", report.Errors)
+	}
+}
@@ -0,0 +1,139 @@
+package dexter
+
+import (
+	"sync"
+	"time"
+)
+
+// ForceKillMode decides what the force kill window is measured from
+type ForceKillMode int
+
+const (
+	// ForceKillWholeShutdown gives the whole shutdown, from the first
+	// target killed until the finalizers ran, one window.  It is the
+	// default.
+	ForceKillWholeShutdown ForceKillMode = iota
+	// ForceKillPerTarget restarts the window whenever a target is killed,
+	// so only a single target stuck for longer than the window forces the
+	// exit
+	ForceKillPerTarget
+	// ForceKillAfterStall starts the window only once a target stalls,
+	// taking longer than its deadline or, without one, than the window
+	ForceKillAfterStall
+)
+
+func (m ForceKillMode) String() string {
+	switch m {
+	case ForceKillWholeShutdown:
+		return "whole shutdown"
+	case ForceKillPerTarget:
+		return "per target"
+	case ForceKillAfterStall:
+		return "after stall"
+	}
+	return "unknown"
+}
+
+// WithForceKillMode sets what the force kill window is measured from, see
+// ForceKillMode.  WaitAndKillContext always bounds the whole shutdown by
+// its context's deadline.
+func WithForceKillMode(mode ForceKillMode) Option {
+	return func(d *Dexter) {
+		d.forceKillMode = mode
+	}
+}
+
+// forceTimer runs expire once the force kill window passed, at most once
+// and never after stop
+type forceTimer struct {
+	window time.Duration
+	expire func()
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	expires time.Time
+	fired   bool
+	stopped bool
+}
+
+// newForceTimer returns a timer for window, it doesn't run until started
+func newForceTimer(window time.Duration, expire func()) *forceTimer {
+	return &forceTimer{window: window, expire: expire}
+}
+
+// start starts the window unless it is already running
+func (f *forceTimer) start() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.timer == nil && !f.fired && !f.stopped {
+		f.timer = time.AfterFunc(f.window, f.fire)
+		f.expires = time.Now().Add(f.window)
+	}
+}
+
+// restart starts the window over
+func (f *forceTimer) restart() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fired || f.stopped {
+		return
+	}
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+	f.timer = time.AfterFunc(f.window, f.fire)
+	f.expires = time.Now().Add(f.window)
+}
+
+// left returns the time until the window expires, false while it isn't
+// running
+func (f *forceTimer) left() (time.Duration, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case f.fired:
+		return 0, true
+	case f.timer == nil || f.stopped:
+		return 0, false
+	}
+	return time.Until(f.expires), true
+}
+
+// fire expires the window right away
+func (f *forceTimer) fire() {
+	f.mu.Lock()
+	if f.fired || f.stopped {
+		f.mu.Unlock()
+		return
+	}
+	f.fired = true
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+	f.mu.Unlock()
+	f.expire()
+}
+
+// stop disarms the timer for good
+func (f *forceTimer) stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stopped = true
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+}
+
+// watchStall starts force once target took longer than its deadline, or
+// than the window without one, stop must be called once it was killed
+func (d *Dexter) watchStall(target *Target, force *forceTimer) (stop func()) {
+	after := d.limitFor(target).deadline
+	if after <= 0 {
+		after = force.window
+	}
+	timer := time.AfterFunc(after, func() {
+		target.logf("Target %s stalled after %v, force killing in %v\n", target.label(), after, force.window)
+		force.start()
+	})
+	return func() { timer.Stop() }
+}
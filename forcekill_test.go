@@ -0,0 +1,66 @@
+package dexter
+
+import (
+	"testing"
+	"time"
+)
+
+// slowTarget returns a target taking d to close
+func slowTarget(name string, d time.Duration) *Target {
+	target := NewTarget(name)
+	target.TrackCloser(closerFunc(func() error {
+		time.Sleep(d)
+		return nil
+	}))
+	return target
+}
+
+func TestForceKillModes(t *testing.T) {
+	for _, tc := range []struct {
+		mode   ForceKillMode
+		forced bool
+	}{
+		{ForceKillWholeShutdown, true},
+		{ForceKillPerTarget, false},
+		{ForceKillAfterStall, false},
+	} {
+		dex := NewDexter(WithManualTrigger(), WithForceKillMode(tc.mode))
+		dex.SetForceKillInterval(60 * time.Millisecond)
+		exited := make(chan struct{}, 1)
+		dex.exitFunc = func(int) {
+			select {
+			case exited <- struct{}{}:
+			default:
+			}
+		}
+		for _, name := range []string{"a", "b", "c"} {
+			dex.Track(slowTarget(name, 30*time.Millisecond))
+		}
+
+		dex.Close()
+		if forced := len(exited) > 0; forced != tc.forced {
+			t.Errorf("%v: forced %v, want %v", tc.mode, forced, tc.forced)
+		}
+	}
+}
+
+func TestForceKillAfterStall(t *testing.T) {
+	dex := NewDexter(WithManualTrigger(), WithForceKillMode(ForceKillAfterStall))
+	dex.SetForceKillInterval(20 * time.Millisecond)
+	exited := make(chan struct{})
+	dex.exitFunc = func(int) { close(exited) }
+	stuck := slowTarget("stuck", 300*time.Millisecond)
+	stuck.SetDeadline(10*time.Millisecond, OverrunWait)
+	dex.Track(stuck)
+
+	started := time.Now()
+	go dex.Close()
+	select {
+	case <-exited:
+		if elapsed := time.Since(started); elapsed < 30*time.Millisecond {
+			t.Errorf("forced after %v, before the stall plus the window", elapsed)
+		}
+	case <-time.After(250 * time.Millisecond):
+		t.Error("stalled target not force killed")
+	}
+}
@@ -0,0 +1,118 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Gate is passed through after a shutdown signal arrived and before any
+// target is killed, it can hold the shutdown back, e.g. until it is this
+// replica's turn to go down
+type Gate interface {
+	// Enter blocks until shutdown may go ahead or ctx is done.  release is
+	// called once shutdown completed.
+	Enter(ctx context.Context) (release func(), err error)
+}
+
+type gate struct {
+	Gate
+	timeout time.Duration
+}
+
+// AddGate adds a gate to pass before killing any target, waiting for it at
+// most timeout.  A gate which fails or times out never blocks the shutdown,
+// its error is recorded in the report and shutdown goes ahead.
+func (d *Dexter) AddGate(g Gate, timeout time.Duration) {
+	d.mu.Lock()
+	d.gates = append(d.gates, gate{Gate: g, timeout: timeout})
+	d.mu.Unlock()
+}
+
+// enterGates passes every gate in order, it returns the funcs releasing
+// them and the errors of the gates which couldn't be passed.  Each gate's
+// context is derived from ctx, so the shutdown's deadline bounds it too.
+func (d *Dexter) enterGates(ctx context.Context) (releases []func(), errs []error) {
+	d.mu.Lock()
+	gates := d.gates
+	d.mu.Unlock()
+
+	for _, g := range gates {
+		gctx, cancel := context.WithTimeout(ctx, g.timeout)
+		start := time.Now()
+		release, err := g.Enter(gctx)
+		cancel()
+		if err != nil {
+			d.logf("Gate %T failed after %v, shutting down anyway: %v\n", g.Gate, time.Since(start), err)
+			errs = append(errs, fmt.Errorf("gate %T: %w", g.Gate, err))
+			continue
+		}
+		if release != nil {
+			releases = append(releases, release)
+		}
+	}
+	return releases, errs
+}
+
+// SlotStore is a store shared by the replicas of a deployment, such as etcd,
+// Consul or Redis, holding counting semaphores
+type SlotStore interface {
+	// TryAcquire takes one of limit slots under key for holder and reports
+	// whether it got one.  Slots must expire after ttl, so a replica which
+	// died holding one doesn't keep it.
+	TryAcquire(ctx context.Context, key, holder string, limit int, ttl time.Duration) (bool, error)
+	// Release gives holder's slot under key back
+	Release(ctx context.Context, key, holder string) error
+}
+
+// StoreGate is a Gate limiting how many replicas may be shutting down at
+// the same time, so a mass SIGTERM during a rollout doesn't drop all
+// capacity at once
+type StoreGate struct {
+	Store  SlotStore
+	Key    string
+	Holder string
+	Limit  int
+	// TTL is how long a slot is held if the replica never releases it
+	TTL time.Duration
+	// Poll is how often to retry while all slots are taken
+	Poll time.Duration
+}
+
+// NewStoreGate returns a gate letting at most limit holders through under
+// key, holder identifies this replica, e.g. its pod name
+func NewStoreGate(store SlotStore, key, holder string, limit int) *StoreGate {
+	return &StoreGate{
+		Store:  store,
+		Key:    key,
+		Holder: holder,
+		Limit:  limit,
+		TTL:    time.Minute,
+		Poll:   time.Second,
+	}
+}
+
+// Enter waits for a free slot
+func (g *StoreGate) Enter(ctx context.Context) (func(), error) {
+	for {
+		ok, err := g.Store.TryAcquire(ctx, g.Key, g.Holder, g.Limit, g.TTL)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return func() {
+				ctx, cancel := context.WithTimeout(context.Background(), g.Poll)
+				defer cancel()
+				if err := g.Store.Release(ctx, g.Key, g.Holder); err != nil {
+					dlog.Printf("Failed to release shutdown slot %s: %v\n", g.Key, err)
+				}
+			}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(g.Poll):
+		}
+	}
+}
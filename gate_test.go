@@ -0,0 +1,106 @@
+package dexter
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore is an in-memory SlotStore
+type memStore struct {
+	mu      sync.Mutex
+	holders map[string]bool
+}
+
+func (m *memStore) TryAcquire(_ context.Context, _, holder string, limit int, _ time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.holders) >= limit {
+		return false, nil
+	}
+	m.holders[holder] = true
+	return true, nil
+}
+
+func (m *memStore) Release(_ context.Context, _, holder string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.holders, holder)
+	return nil
+}
+
+func TestStoreGate(t *testing.T) {
+	store := &memStore{holders: map[string]bool{"replica-1": true}}
+	g := NewStoreGate(store, "deploy/api", "replica-2", 1)
+	g.Poll = time.Millisecond
+
+	entered := make(chan func())
+	go func() {
+		release, err := g.Enter(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		entered <- release
+	}()
+
+	select {
+	case <-entered:
+		t.Fatal("entered the gate while the only slot was taken")
+	case <-time.After(10 * time.Millisecond):
+	}
+	store.Release(context.Background(), "deploy/api", "replica-1")
+	release := <-entered
+	release()
+	if len(store.holders) != 0 {
+		t.Errorf("slot not released: %v", store.holders)
+	}
+}
+
+func TestGateTimeoutDoesNotBlockShutdown(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	store := &memStore{holders: map[string]bool{"replica-1": true}}
+	g := NewStoreGate(store, "deploy/api", "replica-2", 1)
+	g.Poll = time.Millisecond
+	dex.AddGate(g, 10*time.Millisecond)
+	target := NewTarget("workers")
+	dex.Track(target)
+
+	dex.SimulateSignal(os.Interrupt)
+	dex.WaitAndKill()
+
+	report := dex.LastReport()
+	if target.State() != TargetStopped || len(report.Errors) != 1 || report.Clean() {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+// blockingGate is a Gate which is only passed once its context is done
+type blockingGate struct{}
+
+func (blockingGate) Enter(ctx context.Context) (func(), error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestGatesAndDelayHonourContext(t *testing.T) {
+	dex := NewDexter(WithManualTrigger(), WithShutdownDelay(time.Hour))
+	dex.exitFunc = func(int) {}
+	dex.AddGate(blockingGate{}, time.Hour)
+	dex.Track(NewTarget("workers"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	dex.SimulateSignal(os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		dex.WaitAndKillContext(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the gate and the delay ignored the shutdown context")
+	}
+}
@@ -0,0 +1,141 @@
+package dexter
+
+import (
+	"context"
+	"runtime/pprof"
+	"sort"
+)
+
+// Go runs fn on a new goroutine counted in the target's WaitGroup, so the
+// target only finishes draining once fn returned.  fn should return when
+// the resources it works on are closed, e.g. when its input channel is.
+// An error returned by fn is logged, kept for Err and added to the
+// target's errors in the shutdown report.  Like Add, Go panics once the
+// target was killed and drained.
+func (t *Target) Go(fn func() error) {
+	t.GoNamed("", fn)
+}
+
+// GoNamed is Go for a goroutine with a name, e.g. "consumer-7".  Named
+// goroutines still running at the end of a shutdown or at a force kill are
+// reported by name, and carry it as the dexter.goroutine pprof label.
+func (t *Target) GoNamed(name string, fn func() error) {
+	t.Add(1)
+	t.mu.Lock()
+	t.goroutines++
+	if name != "" {
+		if t.named == nil {
+			t.named = map[string]int{}
+		}
+		t.named[name]++
+	}
+	t.mu.Unlock()
+	go func() {
+		defer t.Done()
+		defer func() {
+			t.mu.Lock()
+			t.goroutines--
+			if name != "" {
+				if t.named[name]--; t.named[name] == 0 {
+					delete(t.named, name)
+				}
+			}
+			t.mu.Unlock()
+		}()
+		run := func() {
+			if err := fn(); err != nil {
+				t.goFailed(err)
+			}
+		}
+		if name == "" {
+			run()
+			return
+		}
+		labels := pprof.Labels("dexter.target", t.name, "dexter.goroutine", name)
+		pprof.Do(context.Background(), labels, func(context.Context) { run() })
+	}()
+}
+
+// Running returns the names of the goroutines started with GoNamed which
+// haven't returned yet, sorted.  A name used by several of them is
+// listed once per goroutine.
+func (t *Target) Running() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var names []string
+	for name, n := range t.named {
+		for i := 0; i < n; i++ {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Err returns the first error returned by a goroutine started with Go,
+// nil while none failed
+func (t *Target) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.goErrs) == 0 {
+		return nil
+	}
+	return t.goErrs[0]
+}
+
+// SetKillOnError makes the first goroutine started with Go which fails kill
+// the target, like errgroup cancels its context, the rest of the process
+// keeps running
+func (t *Target) SetKillOnError(kill bool) {
+	t.mu.Lock()
+	t.killOnErr = kill
+	t.mu.Unlock()
+}
+
+// goFailed records err returned by a goroutine and kills the target if it
+// was asked to
+func (t *Target) goFailed(err error) {
+	t.logf("Goroutine in target %s failed: %v\n", t.name, err)
+	t.mu.Lock()
+	t.goErrs = append(t.goErrs, err)
+	kill := t.killOnErr && len(t.goErrs) == 1
+	t.mu.Unlock()
+	if kill {
+		t.logf("Killing target %s after goroutine failure\n", t.name)
+		// the failed goroutine is counted in the WaitGroup Kill waits on
+		go t.Kill()
+	}
+}
+
+// goErrors returns everything the target's goroutines returned
+func (t *Target) goErrors() []error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]error(nil), t.goErrs...)
+}
+
+// reportRunning records the named goroutines of targets which are still
+// running at the end of a shutdown in report and logs them
+func (d *Dexter) reportRunning(report *Report, targets []*Target) {
+	byName := make(map[string]*Target, len(targets))
+	for _, target := range targets {
+		byName[target.name] = target
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, tr := range report.Targets {
+		if target := byName[tr.Name]; target != nil && !tr.Skipped {
+			report.Targets[i].Running = target.Running()
+		}
+	}
+	logRunning(targets)
+}
+
+// logRunning logs every named goroutine of targets which hasn't returned
+func logRunning(targets []*Target) {
+	for _, target := range targets {
+		for _, name := range target.Running() {
+			target.logf("Goroutine %s of target %s didn't exit\n", name, target.label())
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package dexter
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGo(t *testing.T) {
+	target := NewTarget("go")
+	in := make(chan int)
+	target.TrackChannel(in)
+	target.Go(func() error {
+		for range in {
+		}
+		return nil
+	})
+	if n := target.Stats().Goroutines; n != 1 {
+		t.Errorf("%d goroutines running, want 1", n)
+	}
+
+	target.Kill()
+	if n := target.Stats().Goroutines; n != 0 {
+		t.Errorf("%d goroutines survived the kill", n)
+	}
+}
+
+func TestGoErr(t *testing.T) {
+	boom := errors.New("boom")
+	dex := NewDexter(WithManualTrigger())
+	target := NewTarget("go")
+	dex.Track(target)
+	failed := make(chan struct{})
+	target.Go(func() error {
+		defer close(failed)
+		return boom
+	})
+	target.Go(func() error { return nil })
+	<-failed
+
+	if err := target.Err(); err != boom {
+		t.Errorf("Err returned %v", err)
+	}
+	dex.Close()
+	if errs := dex.LastReport().Targets[0].Errors; len(errs) != 1 || errs[0] != boom {
+		t.Errorf("report has %v", errs)
+	}
+}
+
+func TestKillOnError(t *testing.T) {
+	target := NewTarget("go")
+	target.SetKillOnError(true)
+	stopped := target.Watch()
+	in := make(chan int)
+	target.TrackChannel(in)
+	target.Go(func() error {
+		for range in {
+		}
+		return nil
+	})
+	target.Go(func() error { return errors.New("boom") })
+
+	for state := range stopped {
+		if state == TargetStopped {
+			return
+		}
+	}
+	t.Error("target was not killed")
+}
+
+func TestGoNamedRunning(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	target := NewTarget("consumers")
+	target.SetDeadline(20*time.Millisecond, OverrunSkip)
+	dex.Track(target)
+	stuck := make(chan struct{})
+	defer close(stuck)
+	target.GoNamed("consumer-7", func() error {
+		<-stuck
+		return nil
+	})
+	target.GoNamed("consumer-8", func() error { return nil })
+
+	dex.Close()
+	running := dex.LastReport().Targets[0].Running
+	if len(running) != 1 || running[0] != "consumer-7" {
+		t.Errorf("reported %v still running", running)
+	}
+}
@@ -0,0 +1,135 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StreamDrainer counts active streaming RPCs per method.  Closing it
+// cancels the streams' contexts so handlers wind down, and waits up to
+// timeout for them before the server's GracefulStop runs.  It doesn't depend
+// on grpc, wire it up from a stream interceptor:
+//
+//	func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+//		ctx, done := drainer.Begin(ss.Context(), info.FullMethod)
+//		defer done()
+//		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+//	}
+//
+// Track the drainer before the closer calling GracefulStop.
+type StreamDrainer struct {
+	timeout time.Duration
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	mu    sync.Mutex
+	stats map[string]*StreamStats
+}
+
+// StreamStats counts the streams of a single method
+type StreamStats struct {
+	Active int
+	// Drained streams ended after shutdown started
+	Drained int
+}
+
+// NewStreamDrainer returns a drainer waiting up to timeout for streams
+func NewStreamDrainer(timeout time.Duration) *StreamDrainer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &StreamDrainer{
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+		stats:   map[string]*StreamStats{},
+	}
+}
+
+// Begin registers a stream of method, the returned context is derived from
+// ctx and also cancelled when the drainer is closed.  done must be called
+// when the stream's handler returns.
+func (s *StreamDrainer) Begin(ctx context.Context, method string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-s.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	s.mu.Lock()
+	stats := s.stats[method]
+	if stats == nil {
+		stats = &StreamStats{}
+		s.stats[method] = stats
+	}
+	stats.Active++
+	s.mu.Unlock()
+	s.wg.Add(1)
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			cancel()
+			s.mu.Lock()
+			stats.Active--
+			if s.ctx.Err() != nil {
+				stats.Drained++
+			}
+			s.mu.Unlock()
+			s.wg.Done()
+		})
+	}
+}
+
+// Stats returns a copy of the per-method stream counts
+func (s *StreamDrainer) Stats() map[string]StreamStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := make(map[string]StreamStats, len(s.stats))
+	for method, st := range s.stats {
+		stats[method] = *st
+	}
+	return stats
+}
+
+// Close signals every stream to end and waits for them up to the timeout,
+// listing the methods of streams still running in the returned error
+func (s *StreamDrainer) Close() error {
+	s.cancel()
+	drained := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-time.After(s.timeout):
+	}
+
+	var active []string
+	for method, st := range s.Stats() {
+		if st.Active > 0 {
+			active = append(active, fmt.Sprintf("%s (%d)", method, st.Active))
+		}
+	}
+	sort.Strings(active)
+	return fmt.Errorf("streams still active after %v: %s", s.timeout, strings.Join(active, ", "))
+}
+
+// ReportDetails adds per-method drain counts to the shutdown report
+func (s *StreamDrainer) ReportDetails() map[string]string {
+	details := map[string]string{}
+	for method, st := range s.Stats() {
+		details["stream."+method+".drained"] = strconv.Itoa(st.Drained)
+		details["stream."+method+".active"] = strconv.Itoa(st.Active)
+	}
+	return details
+}
@@ -0,0 +1,38 @@
+package dexter
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStreamDrainer(t *testing.T) {
+	drainer := NewStreamDrainer(20 * time.Millisecond)
+
+	// a stream which ends as soon as it is told to
+	ctx, done := drainer.Begin(context.Background(), "/chat.Chat/Subscribe")
+	go func() {
+		<-ctx.Done()
+		done()
+	}()
+	// and one which ignores it
+	_, stuck := drainer.Begin(context.Background(), "/log.Log/Tail")
+	defer stuck()
+
+	dex := NewDexter(WithManualTrigger())
+	target := NewTarget("grpc")
+	target.TrackCloser(drainer)
+	dex.Track(target)
+	dex.SimulateSignal(os.Interrupt)
+	dex.WaitAndKill()
+
+	report := dex.LastReport().Targets[0]
+	if len(report.Errors) != 1 || report.Errors[0].Error() != "streams still active after 20ms: /log.Log/Tail (1)" {
+		t.Errorf("unexpected errors %v", report.Errors)
+	}
+	if report.Details["stream./chat.Chat/Subscribe.drained"] != "1" ||
+		report.Details["stream./log.Log/Tail.active"] != "1" {
+		t.Errorf("unexpected details %v", report.Details)
+	}
+}
@@ -0,0 +1,44 @@
+package dexter
+
+import "time"
+
+// historySize is how many operations History keeps
+const historySize = 32
+
+// Operation is an entry of the history: Kind is "shutdown", "quiesce",
+// "resume" or "reload".  Report is set for shutdowns, Err for the other
+// operations when they failed.
+type Operation struct {
+	Kind     string
+	Started  time.Time
+	Duration time.Duration
+	Err      error
+	Report   *Report
+}
+
+// History returns the most recent shutdowns, quiesces, resumes and
+// reloads, oldest first, so what happened in a previous cycle can still
+// be seen after a restart or maintenance drain
+func (d *Dexter) History() []Operation {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Operation(nil), d.history...)
+}
+
+// record adds an operation which started at started to the history
+func (d *Dexter) record(kind string, started time.Time, err error, report *Report) {
+	op := Operation{
+		Kind:     kind,
+		Started:  started,
+		Duration: time.Since(started),
+		Err:      err,
+		Report:   report,
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.history) == historySize {
+		copy(d.history, d.history[1:])
+		d.history = d.history[:historySize-1]
+	}
+	d.history = append(d.history, op)
+}
@@ -0,0 +1,34 @@
+package dexter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.Quiesce(context.Background())
+	dex.Resume()
+	dex.Shutdown("test")
+	dex.WaitAndKill()
+
+	history := dex.History()
+	if len(history) != 3 {
+		t.Fatalf("unexpected history %+v", history)
+	}
+	for i, kind := range []string{"quiesce", "resume", "shutdown"} {
+		if history[i].Kind != kind {
+			t.Errorf("operation %d is %s, want %s", i, history[i].Kind, kind)
+		}
+	}
+	if history[2].Report == nil || history[2].Report.Reason != "test" {
+		t.Error("shutdown report missing from history")
+	}
+
+	for i := 0; i < historySize; i++ {
+		dex.Resume()
+	}
+	if history := dex.History(); len(history) != historySize || history[0].Kind != "resume" {
+		t.Error("history did not drop the oldest operations")
+	}
+}
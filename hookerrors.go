@@ -0,0 +1,29 @@
+package dexter
+
+// HookErrorPolicy decides what the errors of gates and finalizers do to a
+// shutdown, besides being recorded in the report's Errors, so a failed
+// hook doesn't pass for a clean shutdown with the orchestrator
+type HookErrorPolicy struct {
+	// ExitCode, unless zero, replaces the report's exit code when a gate
+	// or a finalizer failed
+	ExitCode int
+	// Plan, unless nil, replaces the shutdown's plan when a gate failed,
+	// e.g. a fast plan since the state the graceful one relies on is
+	// gone.  Finalizers run too late to change the plan.
+	Plan *KillPlan
+}
+
+// SetHookErrorPolicy sets what errors of gates and finalizers do, by
+// default they are only recorded
+func (d *Dexter) SetHookErrorPolicy(policy HookErrorPolicy) {
+	d.mu.Lock()
+	d.hookErrors = policy
+	d.mu.Unlock()
+}
+
+// hookErrorPolicy returns the policy set with SetHookErrorPolicy
+func (d *Dexter) hookErrorPolicy() HookErrorPolicy {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.hookErrors
+}
@@ -0,0 +1,42 @@
+package dexter
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHookErrorPolicy(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	fast := KillPlan{Name: "fast", Skip: func(target *Target) bool { return target.Phase() == PhaseFlush }}
+	dex.SetHookErrorPolicy(HookErrorPolicy{ExitCode: 70, Plan: &fast})
+	store := &memStore{holders: map[string]bool{"replica-1": true}}
+	dex.AddGate(NewStoreGate(store, "deploy/api", "replica-2", 1), 10*time.Millisecond)
+	dex.TrackPhase(PhaseFlush, NewTarget("buffers"))
+
+	dex.Close()
+	report := dex.LastReport()
+	if report.Plan != "fast" || !report.Targets[0].Skipped {
+		t.Errorf("plan %s, targets %+v", report.Plan, report.Targets)
+	}
+	if dex.ExitCode() != 70 {
+		t.Errorf("exit code %d", dex.ExitCode())
+	}
+}
+
+func TestHookErrorPolicyFinalizer(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.SetExitCode("close", 3)
+	dex.Close()
+	if dex.ExitCode() != 3 {
+		t.Fatalf("exit code %d without errors", dex.ExitCode())
+	}
+
+	dex = NewDexter(WithManualTrigger())
+	dex.SetHookErrorPolicy(HookErrorPolicy{ExitCode: 70})
+	dex.Finalize("state", func() error { return errors.New("persisting state failed") })
+	dex.Close()
+	if dex.ExitCode() != 70 {
+		t.Errorf("exit code %d", dex.ExitCode())
+	}
+}
@@ -0,0 +1,63 @@
+package dexter
+
+import (
+	"context"
+	"net"
+)
+
+// Context returns a context which is canceled when the target is killed,
+// with its funcs, before its closers are closed and before it waits for
+// its WaitGroup.  Every call returns the same context.
+func (t *Target) Context() context.Context {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.ctx == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.ctx = ctx
+		if t.state != TargetRunning || t.funcsRun {
+			cancel()
+		} else {
+			t.funcs = append(t.funcs, trackedFunc{name: "context", fn: cancel})
+		}
+	}
+	return t.ctx
+}
+
+// BaseContext returns a func for http.Server.BaseContext, the context of
+// every request served is canceled when the target is killed
+func (t *Target) BaseContext() func(net.Listener) context.Context {
+	return func(net.Listener) context.Context {
+		return t.Context()
+	}
+}
+
+// ConnContext returns a func for http.Server.ConnContext, the context of
+// every request served is canceled when the target is killed while keeping
+// the values of the server's base context.  With BaseContext from an
+// earlier target, such as the ingress, requests can keep running until the
+// stage doing the work is killed.
+func (t *Target) ConnContext() func(context.Context, net.Conn) context.Context {
+	return func(ctx context.Context, _ net.Conn) context.Context {
+		return valuesFrom{Context: t.Context(), values: ctx}
+	}
+}
+
+// valuesFrom is a context canceled like its Context but with the values
+// of another
+type valuesFrom struct {
+	context.Context
+	values context.Context
+}
+
+func (c valuesFrom) Value(key interface{}) interface{} {
+	return c.values.Value(key)
+}
+
+// BaseContext returns a func for http.Server.BaseContext, the context of
+// every request served is canceled as soon as shutdown starts, see
+// SignalContext
+func (d *Dexter) BaseContext() func(net.Listener) context.Context {
+	return func(net.Listener) context.Context {
+		return d.SignalContext()
+	}
+}
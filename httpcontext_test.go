@@ -0,0 +1,49 @@
+package dexter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServerContexts(t *testing.T) {
+	ingress, workers := NewTarget("ingress"), NewTarget("workers")
+	started := make(chan context.Context, 1)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- r.Context()
+		<-r.Context().Done()
+	}))
+	srv.Config.BaseContext = ingress.BaseContext()
+	srv.Config.ConnContext = workers.ConnContext()
+	srv.Start()
+	defer srv.Close()
+
+	go http.Get(srv.URL)
+	ctx := <-started
+	if ctx.Value(http.ServerContextKey) == nil {
+		t.Error("request context lost the server's values")
+	}
+
+	ingress.Kill()
+	select {
+	case <-ctx.Done():
+		t.Fatal("request canceled with the ingress")
+	case <-time.After(20 * time.Millisecond):
+	}
+	workers.Kill()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Error("request not canceled when the workers were killed")
+	}
+}
+
+func TestTargetContextAfterKill(t *testing.T) {
+	target := NewTarget("late")
+	target.Kill()
+	if target.Context().Err() == nil {
+		t.Error("context of a killed target is not canceled")
+	}
+}
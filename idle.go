@@ -0,0 +1,53 @@
+package dexter
+
+import (
+	"context"
+	"time"
+)
+
+// ShutdownWhenIdle starts the graceful shutdown with reason "idle" once
+// none of trackers had any work for idle, so scale-to-zero workloads such
+// as queue consumers exit cleanly and the autoscaler can reap them.  Work
+// in progress always keeps the process up.  Watching stops when shutdown
+// starts for any other reason.
+func (d *Dexter) ShutdownWhenIdle(idle time.Duration, trackers ...*InFlight) {
+	d.addTrigger("idle-watchdog", IdleTrigger(idle, trackers...))
+}
+
+// IdleTrigger starts shutdown with reason "idle" once none of trackers had
+// any work for idle, see ShutdownWhenIdle
+func IdleTrigger(idle time.Duration, trackers ...*InFlight) Trigger {
+	return TriggerFunc(func(stop context.Context) (string, bool) {
+		for {
+			wait, idleFor := idle, time.Duration(0)
+			if since, busy := lastActivity(trackers); !busy {
+				idleFor = time.Since(since)
+				if idleFor >= idle {
+					dlog.Printf("Idle for %v, shutting down\n", idleFor.Round(time.Millisecond))
+					return "idle", true
+				}
+				wait = idle - idleFor
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-stop.Done():
+				timer.Stop()
+				return "", false
+			}
+		}
+	})
+}
+
+// lastActivity returns when work last began or ended on any of trackers,
+// busy is set while any of them has work in progress
+func lastActivity(trackers []*InFlight) (last time.Time, busy bool) {
+	for _, f := range trackers {
+		since, b := f.idleSince()
+		busy = busy || b
+		if since.After(last) {
+			last = since
+		}
+	}
+	return last, busy
+}
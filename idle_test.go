@@ -0,0 +1,32 @@
+package dexter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShutdownWhenIdle(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	target := NewTarget("consumer")
+	dex.Track(target)
+	work := NewInFlight(target)
+	started := time.Now()
+	dex.ShutdownWhenIdle(50*time.Millisecond, work)
+
+	done, _ := work.Begin()
+	time.Sleep(80 * time.Millisecond)
+	select {
+	case <-dex.Stopping():
+		t.Fatal("shut down with work in progress")
+	default:
+	}
+	done()
+
+	dex.WaitAndKill()
+	if reason := dex.LastReport().Reason; reason != "idle" {
+		t.Errorf("shut down for %q", reason)
+	}
+	if elapsed := time.Since(started); elapsed < 130*time.Millisecond {
+		t.Errorf("shut down after %v, before being idle long enough", elapsed)
+	}
+}
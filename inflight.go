@@ -0,0 +1,115 @@
+package dexter
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// InFlight counts units of work in progress on a target, such as HTTP
+// requests, so that the target waits for them when it is killed.  It is
+// also a Quiescer on its target: while quiesced new work is rejected.
+type InFlight struct {
+	target *Target
+
+	mu      sync.Mutex
+	count   int
+	paused  bool
+	drained chan struct{}
+	// last is when work last began or ended, see Dexter.ShutdownWhenIdle
+	last time.Time
+}
+
+// NewInFlight returns an in-flight tracker adding its work to target
+func NewInFlight(target *Target) *InFlight {
+	f := &InFlight{target: target, last: time.Now()}
+	target.TrackQuiescer(f)
+	return f
+}
+
+// Begin records the start of a unit of work, done must be called when it
+// ends.  ok is false while quiesced and once the target has been killed,
+// the work should be rejected then.
+func (f *InFlight) Begin() (done func(), ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.paused || !f.target.tryAdd() {
+		return func() {}, false
+	}
+	f.count++
+	f.last = time.Now()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			f.mu.Lock()
+			f.last = time.Now()
+			if f.count--; f.count == 0 && f.drained != nil {
+				close(f.drained)
+				f.drained = nil
+			}
+			f.mu.Unlock()
+			f.target.endLease()
+		})
+	}, true
+}
+
+// Quiesce rejects new work and waits for the work in progress to finish
+func (f *InFlight) Quiesce(ctx context.Context) error {
+	f.mu.Lock()
+	f.paused = true
+	if f.count == 0 {
+		f.mu.Unlock()
+		return nil
+	}
+	if f.drained == nil {
+		f.drained = make(chan struct{})
+	}
+	drained := f.drained
+	f.mu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Resume accepts new work again
+func (f *InFlight) Resume() error {
+	f.mu.Lock()
+	f.paused = false
+	f.mu.Unlock()
+	return nil
+}
+
+// Count returns how many units of work are in progress
+func (f *InFlight) Count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.count
+}
+
+// Handler tracks every request served by next, requests arriving after the
+// target was killed are answered with 503 Service Unavailable
+func (f *InFlight) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		done, ok := f.Begin()
+		if !ok {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		defer done()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// idleSince returns when work last began or ended, busy is set while work
+// is in progress
+func (f *InFlight) idleSince() (since time.Time, busy bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.last, f.count > 0
+}
@@ -0,0 +1,170 @@
+package dexter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrIntakePaused is returned by Intake.Send while the intake is paused
+// with RejectWhilePaused
+var ErrIntakePaused = errors.New("intake is paused")
+
+// ErrIntakeClosed is returned by Intake.Send once the target was killed
+var ErrIntakeClosed = errors.New("intake is closed")
+
+// PauseMode decides what sends do while an Intake is paused
+type PauseMode int
+
+const (
+	// BlockWhilePaused makes sends wait for Resume, or for the target to be
+	// killed
+	BlockWhilePaused PauseMode = iota
+	// RejectWhilePaused makes sends fail with ErrIntakePaused
+	RejectWhilePaused
+)
+
+// intakeDrainPoll is how often Quiesce checks whether the channel drained
+const intakeDrainPoll = time.Millisecond
+
+// Intake guards a tracked channel feeding a target work, so it can be
+// paused by Dexter.Quiesce, for maintenance, without being closed and
+// resumed later.  Senders must use Send instead of sending on the channel.
+// When the target is killed sends are refused and the channel is closed
+// like any tracked channel.
+type Intake[T any] struct {
+	ch   chan T
+	mode PauseMode
+
+	mu     sync.Mutex
+	paused chan struct{}
+	closed bool
+	// sends counts the sends in progress, so the channel isn't closed under
+	// them, idle is closed once the last one returned
+	sends int
+	idle  chan struct{}
+}
+
+// NewIntake tracks ch on t behind an intake pausing sends as mode says
+func NewIntake[T any](t *Target, ch chan T, mode PauseMode) *Intake[T] {
+	in := &Intake[T]{ch: ch, mode: mode}
+	t.TrackChannel(ch)
+	t.trackFuncNamed("intake", in.stop)
+	t.TrackQuiescer(in)
+	return in
+}
+
+// Send sends v on the channel unless the intake is paused or closed, or
+// ctx is done first
+func (in *Intake[T]) Send(ctx context.Context, v T) error {
+	for {
+		in.mu.Lock()
+		if in.closed {
+			in.mu.Unlock()
+			return ErrIntakeClosed
+		}
+		if in.paused == nil {
+			if in.sends++; in.idle == nil {
+				in.idle = make(chan struct{})
+			}
+			in.mu.Unlock()
+			break
+		}
+		if in.mode == RejectWhilePaused {
+			in.mu.Unlock()
+			return ErrIntakePaused
+		}
+		resumed := in.paused
+		in.mu.Unlock()
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	defer in.sent()
+	select {
+	case in.ch <- v:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sent ends a send in progress
+func (in *Intake[T]) sent() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.sends--; in.sends == 0 {
+		close(in.idle)
+		in.idle = nil
+	}
+}
+
+// sending returns a channel closed once the sends in progress returned,
+// nil when there are none
+func (in *Intake[T]) sending() <-chan struct{} {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.idle
+}
+
+// Paused reports whether the intake is paused
+func (in *Intake[T]) Paused() bool {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.paused != nil
+}
+
+// Quiesce pauses the intake and waits for the sends in progress and for
+// the channel's buffer to drain, or for ctx to be done
+func (in *Intake[T]) Quiesce(ctx context.Context) error {
+	in.mu.Lock()
+	if in.paused == nil && !in.closed {
+		in.paused = make(chan struct{})
+	}
+	in.mu.Unlock()
+
+	if idle := in.sending(); idle != nil {
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	for len(in.ch) > 0 {
+		select {
+		case <-time.After(intakeDrainPoll):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Resume takes sends again
+func (in *Intake[T]) Resume() error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.paused != nil {
+		close(in.paused)
+		in.paused = nil
+	}
+	return nil
+}
+
+// stop refuses sends from now on and waits for those in progress, before
+// the target closes the channel
+func (in *Intake[T]) stop() {
+	in.mu.Lock()
+	in.closed = true
+	if in.paused != nil {
+		close(in.paused)
+		in.paused = nil
+	}
+	in.mu.Unlock()
+	if idle := in.sending(); idle != nil {
+		<-idle
+	}
+}
@@ -0,0 +1,85 @@
+package dexter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIntakePause(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	target := NewTarget("workers")
+	jobs := make(chan int, 4)
+	intake := NewIntake(target, jobs, BlockWhilePaused)
+	dex.Track(target)
+	consumed := make(chan int, 4)
+	Consume(target, jobs, func(n int) { consumed <- n })
+
+	ctx := context.Background()
+	intake.Send(ctx, 1)
+	if err := dex.Quiesce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !intake.Paused() || len(jobs) != 0 {
+		t.Fatalf("paused %v, %d jobs queued", intake.Paused(), len(jobs))
+	}
+	sent := make(chan error, 1)
+	go func() { sent <- intake.Send(ctx, 2) }()
+	select {
+	case err := <-sent:
+		t.Fatalf("send went through a paused intake: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	dex.Resume()
+	if err := <-sent; err != nil {
+		t.Fatal(err)
+	}
+	dex.Close()
+	if len(consumed) != 2 {
+		t.Errorf("consumed %d jobs", len(consumed))
+	}
+	if err := intake.Send(ctx, 3); err != ErrIntakeClosed {
+		t.Errorf("send after kill: %v", err)
+	}
+}
+
+func TestIntakeReject(t *testing.T) {
+	target := NewTarget("workers")
+	intake := NewIntake(target, make(chan string, 1), RejectWhilePaused)
+	intake.Quiesce(context.Background())
+	if err := intake.Send(context.Background(), "job"); err != ErrIntakePaused {
+		t.Errorf("got %v", err)
+	}
+	target.Kill()
+	if err := intake.Send(context.Background(), "job"); err != ErrIntakeClosed {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestIntakeQuiesceDeadline(t *testing.T) {
+	target := NewTarget("workers")
+	intake := NewIntake(target, make(chan int), BlockWhilePaused)
+	sendCtx, cancelSend := context.WithCancel(context.Background())
+	defer cancelSend()
+	sending := make(chan error, 1)
+	go func() { sending <- intake.Send(sendCtx, 1) }()
+	for intake.sending() == nil {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	if err := intake.Quiesce(ctx); err != context.DeadlineExceeded {
+		t.Errorf("got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("quiesce took %v with a blocked send", elapsed)
+	}
+	cancelSend()
+	if err := <-sending; err != context.Canceled {
+		t.Errorf("send returned %v", err)
+	}
+	target.Kill()
+}
@@ -0,0 +1,44 @@
+package dexter
+
+// Killed returns a channel which is closed once the target's first kill
+// finished, or was given up on after the target overran its deadline, so
+// other parts of the application can sequence on a stage without being
+// tracked themselves.  It is never closed for a target left out of the
+// shutdown.  KillErr then tells how the kill went.
+func (t *Target) Killed() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.killed == nil {
+		t.killed = make(chan struct{})
+	}
+	return t.killed
+}
+
+// KillErr returns a *KillError holding the errors of the target's first
+// kill, nil until Killed is closed and when the kill was clean
+func (t *Target) KillErr() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.killErr == nil {
+		return nil
+	}
+	return t.killErr
+}
+
+// finishKill records the outcome of the target's kill and closes Killed,
+// only the first call does anything
+func (t *Target) finishKill(errs []error, overrun bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.killDone {
+		return
+	}
+	t.killDone = true
+	if len(errs) > 0 || overrun {
+		t.killErr = &KillError{Target: t.name, Errs: errs, Overrun: overrun}
+	}
+	if t.killed == nil {
+		t.killed = make(chan struct{})
+	}
+	close(t.killed)
+}
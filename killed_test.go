@@ -0,0 +1,34 @@
+package dexter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestKilled(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	db := NewTarget("db")
+	db.TrackCloser(closerFunc(func() error { return errors.New("flush failed") }))
+	dex.Track(db)
+	killed := db.Killed()
+
+	select {
+	case <-killed:
+		t.Fatal("killed before shutdown")
+	default:
+	}
+	if err := db.KillErr(); err != nil {
+		t.Fatalf("kill error before shutdown: %v", err)
+	}
+
+	dex.Close()
+	<-killed
+	var kerr *KillError
+	if !errors.As(db.KillErr(), &kerr) || len(kerr.Errs) != 1 {
+		t.Errorf("got %v", db.KillErr())
+	}
+	db.Kill()
+	if db.KillErr() == nil {
+		t.Error("second kill cleared the error")
+	}
+}
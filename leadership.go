@@ -0,0 +1,67 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PhaseLeadership is killed first, as soon as shutdown starts, so
+// leadership is handed over before any worker stops
+const PhaseLeadership Phase = 50
+
+// Leadership is implemented by leader election clients, e.g. wrapping a
+// Kubernetes lease or an etcd election
+type Leadership interface {
+	// Resign gives up leadership
+	Resign(ctx context.Context) error
+}
+
+// SuccessorObserver is optionally implemented by a Leadership which can
+// confirm another replica observed the resignation
+type SuccessorObserver interface {
+	// AwaitSuccessor returns once a successor has taken over, or ctx is done
+	AwaitSuccessor(ctx context.Context) error
+}
+
+// TrackLeadership tracks a target called name in PhaseLeadership which
+// resigns leadership and, if leader is a SuccessorObserver, waits for a
+// successor to confirm, all within timeout.  The target is returned so
+// more resources can be added to it.
+func (d *Dexter) TrackLeadership(name string, leader Leadership, timeout time.Duration) *Target {
+	target := NewTarget(name)
+	target.TrackCloser(resigner{leader: leader, timeout: timeout, target: target})
+	d.TrackPhase(PhaseLeadership, target)
+	return target
+}
+
+type resigner struct {
+	leader  Leadership
+	timeout time.Duration
+	// target logs the handover, with its logger and fields
+	target *Target
+}
+
+func (r resigner) Close() error {
+	return r.CloseWithContext(context.Background())
+}
+
+// CloseWithContext resigns and awaits the successor within the timeout,
+// or until ctx is done if that comes first
+func (r resigner) CloseWithContext(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := r.leader.Resign(ctx); err != nil {
+		return fmt.Errorf("resigning leadership: %w", err)
+	}
+	observer, ok := r.leader.(SuccessorObserver)
+	if !ok {
+		return nil
+	}
+	if err := observer.AwaitSuccessor(ctx); err != nil {
+		return fmt.Errorf("no successor observed the resignation within %v: %w", r.timeout, err)
+	}
+	r.target.logf("Successor observed leadership resignation\n")
+	return nil
+}
@@ -0,0 +1,62 @@
+package dexter
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeLeader struct {
+	resigned  bool
+	successor bool
+}
+
+func (l *fakeLeader) Resign(context.Context) error {
+	l.resigned = true
+	return nil
+}
+
+func (l *fakeLeader) AwaitSuccessor(ctx context.Context) error {
+	if l.successor {
+		return nil
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestTrackLeadership(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.TrackPhase(PhaseIngress, NewTarget("http"))
+	leader := &fakeLeader{}
+	dex.TrackLeadership("election", leader, 10*time.Millisecond)
+
+	dex.SimulateSignal(os.Interrupt)
+	dex.WaitAndKill()
+
+	report := dex.LastReport()
+	if report.Targets[0].Name != "election" || !leader.resigned {
+		t.Fatal("leadership was not resigned first")
+	}
+	errs := report.Targets[0].Errors
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "no successor observed") {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestLeadershipLogsThroughTarget(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	target := dex.TrackLeadership("election", &fakeLeader{successor: true}, time.Hour)
+	var buf bytes.Buffer
+	target.SetLogger(log.New(&buf, "", 0))
+
+	dex.SimulateSignal(os.Interrupt)
+	dex.WaitAndKill()
+
+	if !strings.Contains(buf.String(), "Successor observed leadership resignation") {
+		t.Errorf("handover not logged through the target: %q", buf.String())
+	}
+}
@@ -0,0 +1,47 @@
+package dexter
+
+import (
+	"fmt"
+	"sync"
+)
+
+// OnStart registers fn as a start function, start functions build the
+// application's targets and Track them.  They are run in order by Start,
+// and again by Restart after the old targets were killed.
+func (d *Dexter) OnStart(fn func() error) {
+	d.mu.Lock()
+	d.starts = append(d.starts, fn)
+	d.mu.Unlock()
+}
+
+// Start runs the start functions in order, stopping at the first error,
+// and marks d Ready once all of them succeeded
+func (d *Dexter) Start() error {
+	d.mu.Lock()
+	starts := d.starts
+	d.mu.Unlock()
+	for i, fn := range starts {
+		if err := fn(); err != nil {
+			return fmt.Errorf("start function %d: %w", i, err)
+		}
+	}
+	d.Ready()
+	return nil
+}
+
+// Restart kills every target, as a shutdown with reason "restart" would,
+// forgets them and runs the start functions again.  The process keeps its
+// PID and supervisor session, WaitAndKill keeps waiting and will kill the
+// new targets.
+func (d *Dexter) Restart() error {
+	d.logf("Restarting\n")
+	d.shutdown(trigger{reason: "restart", restart: true})
+
+	d.mu.Lock()
+	d.targets = []*Target{}
+	d.stopping = make(chan struct{})
+	d.stoppingOnce = sync.Once{}
+	d.mu.Unlock()
+	d.startLifetime()
+	return d.Start()
+}
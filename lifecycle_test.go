@@ -0,0 +1,68 @@
+package dexter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRestart(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	var generations []*Target
+	dex.OnStart(func() error {
+		target := NewTarget("listener")
+		generations = append(generations, target)
+		dex.Track(target)
+		return nil
+	})
+
+	if err := dex.Start(); err != nil {
+		t.Fatal(err)
+	}
+	stopping := dex.Stopping()
+	if err := dex.Restart(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(generations) != 2 || generations[0].State() != TargetStopped || generations[1].State() != TargetRunning {
+		t.Fatal("restart did not replace the old generation")
+	}
+	if order := dex.killOrder(); len(order) != 1 || order[0] != generations[1] {
+		t.Error("old targets are still tracked after restart")
+	}
+	if dex.LastReport().Reason != "restart" || !dex.Serving() {
+		t.Error("restart left the process stopping")
+	}
+	select {
+	case <-stopping:
+	default:
+		t.Error("handlers of the old generation were not told to stop")
+	}
+}
+
+func TestStartError(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.OnStart(func() error { return errors.New("port in use") })
+	if err := dex.Start(); err == nil || err.Error() != "start function 0: port in use" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestShutdownReasonRestart(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	var finalized bool
+	dex.Finalize("flush", func() error {
+		finalized = true
+		return nil
+	})
+
+	dex.Shutdown("restart")
+	dex.WaitAndKill()
+	if !finalized {
+		t.Error("a shutdown for reason restart skipped the finalizers")
+	}
+	first := dex.LastReport()
+	dex.Close()
+	if dex.LastReport() != first {
+		t.Error("close shut down again after a shutdown for reason restart")
+	}
+}
@@ -0,0 +1,67 @@
+package dexter
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// WithMaxLifetime starts the graceful shutdown with reason "ttl" once the
+// process ran for d, plus or minus a random jitter so a fleet started
+// together isn't recycled together.  Use SetExitCode("ttl", code) to tell
+// the supervisor to restart the process.
+func WithMaxLifetime(d, jitter time.Duration) Option {
+	return func(dex *Dexter) {
+		dex.maxLifetime = d
+		if jitter > 0 {
+			dex.maxLifetime += time.Duration(rand.Int63n(int64(2*jitter+1))) - jitter
+		}
+	}
+}
+
+// startLifetime arms the max lifetime trigger for the lifetime left, it is
+// armed again after a Restart
+func (d *Dexter) startLifetime() {
+	if d.maxLifetime <= 0 {
+		return
+	}
+	lifetime := d.maxLifetime
+	d.logf("Shutting down after a lifetime of %v\n", lifetime.Round(time.Millisecond))
+	timer := TimerTrigger(time.Until(d.born.Add(lifetime)), "ttl")
+	d.addTrigger("lifetime", TriggerFunc(func(stop context.Context) (string, bool) {
+		reason, ok := timer.Watch(stop)
+		if ok {
+			d.logf("Reached the maximum lifetime of %v\n", lifetime.Round(time.Millisecond))
+		}
+		return reason, ok
+	}))
+}
+
+// SetExitCode sets the exit code ExitCode reports for shutdowns started
+// for reason, e.g. SetExitCode("ttl", 75) so a supervisor can tell a
+// planned recycle from a stop
+func (d *Dexter) SetExitCode(reason string, code int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.exitCodes == nil {
+		d.exitCodes = map[string]int{}
+	}
+	d.exitCodes[reason] = code
+}
+
+// ExitCode returns the code the process should exit with after the last
+// shutdown, the one set for its reason with SetExitCode and 0 otherwise
+func (d *Dexter) ExitCode() int {
+	report := d.LastReport()
+	if report == nil {
+		return 0
+	}
+	return report.ExitCode
+}
+
+// exitCodeFor returns the exit code set for reason
+func (d *Dexter) exitCodeFor(reason string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.exitCodes[reason]
+}
@@ -0,0 +1,30 @@
+package dexter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaxLifetime(t *testing.T) {
+	started := time.Now()
+	dex := NewDexter(WithManualTrigger(), WithMaxLifetime(40*time.Millisecond, 10*time.Millisecond))
+	dex.SetExitCode("ttl", 75)
+
+	dex.WaitAndKill()
+	if elapsed := time.Since(started); elapsed < 30*time.Millisecond {
+		t.Errorf("shut down after %v", elapsed)
+	}
+	if report := dex.LastReport(); report.Reason != "ttl" || dex.ExitCode() != 75 {
+		t.Errorf("shut down for %q with exit code %d", report.Reason, dex.ExitCode())
+	}
+}
+
+func TestMaxLifetimeStopsWithShutdown(t *testing.T) {
+	dex := NewDexter(WithManualTrigger(), WithMaxLifetime(20*time.Millisecond, 0))
+	dex.Close()
+
+	time.Sleep(50 * time.Millisecond)
+	if len(dex.triggers) != 0 {
+		t.Error("lifetime requested a shutdown after the dexter was closed")
+	}
+}
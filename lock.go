@@ -0,0 +1,77 @@
+package dexter
+
+import (
+	"os"
+	"sync"
+)
+
+// Lock is a lock held by the process which has to be released when it
+// exits, leaked locks keep a replacement process from starting
+type Lock interface {
+	Unlock() error
+}
+
+// LockFile returns a Lock which is released by removing the lock file at path
+func LockFile(path string) Lock {
+	return lockFile(path)
+}
+
+type lockFile string
+
+func (l lockFile) Unlock() error {
+	err := os.Remove(string(l))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// trackedLock makes sure a lock is only released once, whether that
+// happens during the normal kill or from the force kill handler
+type trackedLock struct {
+	Lock
+	once sync.Once
+}
+
+// release unlocks the lock, ok is false if it had already been released
+func (l *trackedLock) release() (ok bool, err error) {
+	l.once.Do(func() {
+		ok, err = true, l.Unlock()
+	})
+	return ok, err
+}
+
+// TrackLock releases lock once the target has been killed and drained.
+// If the process is force killed first, the lock is released right before
+// exiting instead.  A target abandoned with WithNoForceExit keeps its locks
+// until it drains.
+func (t *Target) TrackLock(lock Lock) {
+	t.mu.Lock()
+	t.locks = append(t.locks, &trackedLock{Lock: lock})
+	t.mu.Unlock()
+}
+
+// releaseLocks releases every lock held by the target, or targets it
+// adopted, which hasn't been released yet
+func (t *Target) releaseLocks() []error {
+	t.mu.Lock()
+	locks, adopted := t.locks, t.adopted
+	t.mu.Unlock()
+
+	var errs []error
+	for _, lock := range locks {
+		ok, err := lock.release()
+		if !ok {
+			continue
+		}
+		if err != nil {
+			t.logf("Error releasing lock %T in target %s: %v\n", lock.Lock, t.name, err)
+			errs = append(errs, err)
+		}
+		t.released(ResourceLock, resourceName(lock.Lock), lock.Lock, err)
+	}
+	for _, other := range adopted {
+		errs = append(errs, other.releaseLocks()...)
+	}
+	return errs
+}
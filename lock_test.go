@@ -0,0 +1,28 @@
+package dexter
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestTrackLockFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "dexter-lock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	target := NewTarget("locked")
+	target.TrackLock(LockFile(f.Name()))
+	target.Kill()
+
+	if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+		os.Remove(f.Name())
+		t.Fatal("lock file was not removed")
+	}
+	// releasing again, e.g. from the force kill handler, is a no-op
+	if errs := target.releaseLocks(); len(errs) != 0 {
+		t.Errorf("second release returned %v", errs)
+	}
+}
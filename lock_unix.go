@@ -0,0 +1,39 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package dexter
+
+import (
+	"os"
+	"syscall"
+)
+
+// Flock returns a Lock releasing the flock(2) lock held on f
+func Flock(f *os.File) Lock {
+	return flock{f}
+}
+
+type flock struct {
+	f *os.File
+}
+
+func (l flock) Unlock() error {
+	return syscall.Flock(int(l.f.Fd()), syscall.LOCK_UN)
+}
+
+// FcntlLock returns a Lock releasing the fcntl(2) record lock held on the
+// whole of f
+func FcntlLock(f *os.File) Lock {
+	return fcntlLock{f}
+}
+
+type fcntlLock struct {
+	f *os.File
+}
+
+func (l fcntlLock) Unlock() error {
+	return syscall.FcntlFlock(l.f.Fd(), syscall.F_SETLK, &syscall.Flock_t{
+		Type:   syscall.F_UNLCK,
+		Whence: 0,
+	})
+}
@@ -0,0 +1,19 @@
+//go:build go1.19
+// +build go1.19
+
+package dexter
+
+import (
+	"math"
+	"runtime/debug"
+)
+
+// goMemoryLimit returns the runtime's soft memory limit set with
+// GOMEMLIMIT or debug.SetMemoryLimit, 0 if there is none
+func goMemoryLimit() uint64 {
+	limit := debug.SetMemoryLimit(-1)
+	if limit <= 0 || limit == math.MaxInt64 {
+		return 0
+	}
+	return uint64(limit)
+}
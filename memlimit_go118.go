@@ -0,0 +1,9 @@
+//go:build !go1.19
+// +build !go1.19
+
+package dexter
+
+// goMemoryLimit returns 0, soft memory limits only exist since Go 1.19
+func goMemoryLimit() uint64 {
+	return 0
+}
@@ -0,0 +1,82 @@
+package dexter
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// MemoryWatch configures ShutdownOnMemoryPressure
+type MemoryWatch struct {
+	// Limit is the memory the process may use, in bytes.  When it is zero
+	// the cgroup memory limit is used, or GOMEMLIMIT if there is none.
+	Limit uint64
+	// Threshold is the fraction of Limit at which shutdown starts, 0.9 if
+	// it is zero
+	Threshold float64
+	// Interval is how often memory use is checked, every second if it is
+	// zero
+	Interval time.Duration
+}
+
+// ErrNoMemoryLimit is returned by ShutdownOnMemoryPressure when no limit
+// was given and none could be detected
+var ErrNoMemoryLimit = errors.New("dexter: no memory limit set or detected")
+
+// ShutdownOnMemoryPressure starts the graceful shutdown with reason
+// "memory pressure" once memory use crosses the watch's threshold, so the
+// process drains in order instead of losing in-flight work to the OOM
+// killer.  Under a cgroup limit the cgroup's usage is watched, otherwise
+// the process's resident memory.  Watching stops when shutdown starts for
+// any other reason.
+func (d *Dexter) ShutdownOnMemoryPressure(watch MemoryWatch) error {
+	usage := processMemory
+	if watch.Limit == 0 {
+		if _, limit, ok := cgroupMemory(); ok {
+			watch.Limit = limit
+			usage = func() (uint64, error) {
+				used, _, _ := cgroupMemory()
+				return used, nil
+			}
+		} else {
+			watch.Limit = goMemoryLimit()
+		}
+	}
+	if watch.Limit == 0 {
+		return ErrNoMemoryLimit
+	}
+	if watch.Threshold <= 0 {
+		watch.Threshold = 0.9
+	}
+	if watch.Interval <= 0 {
+		watch.Interval = time.Second
+	}
+	d.watchMemory(watch, usage)
+	return nil
+}
+
+// watchMemory polls usage until it crosses the threshold or shutdown starts
+func (d *Dexter) watchMemory(watch MemoryWatch, usage func() (uint64, error)) {
+	threshold := uint64(float64(watch.Limit) * watch.Threshold)
+	d.addTrigger("memory-watch", TriggerFunc(func(stop context.Context) (string, bool) {
+		ticker := time.NewTicker(watch.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-stop.Done():
+				return "", false
+			}
+			used, err := usage()
+			if err != nil {
+				d.logf("Reading memory use: %v\n", err)
+				continue
+			}
+			if used >= threshold {
+				d.logf("Using %d of %d bytes of memory, shutting down\n", used, watch.Limit)
+				d.metrics.Count("shutdown.memory_pressure", 1)
+				return "memory pressure", true
+			}
+		}
+	}))
+}
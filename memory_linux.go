@@ -0,0 +1,55 @@
+package dexter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// cgroupMemory returns the usage and limit of the process's memory cgroup,
+// ok is false when there is no limited cgroup
+func cgroupMemory() (usage, limit uint64, ok bool) {
+	for _, files := range [][2]string{
+		{"/sys/fs/cgroup/memory.current", "/sys/fs/cgroup/memory.max"},
+		{"/sys/fs/cgroup/memory/memory.usage_in_bytes", "/sys/fs/cgroup/memory/memory.limit_in_bytes"},
+	} {
+		limit, err := readUint(files[1])
+		// cgroup v1 reports no limit as a huge number rather than "max"
+		if err != nil || limit == 0 || limit >= 1<<62 {
+			continue
+		}
+		usage, err := readUint(files[0])
+		if err != nil {
+			continue
+		}
+		return usage, limit, true
+	}
+	return 0, 0, false
+}
+
+// processMemory returns the resident memory of the process
+func processMemory() (uint64, error) {
+	data, err := ioutil.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("unexpected /proc/self/statm: %q", data)
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return pages * uint64(os.Getpagesize()), nil
+}
+
+func readUint(path string) (uint64, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+// +build !linux
+
+package dexter
+
+import "runtime"
+
+// cgroupMemory reports that there is no memory cgroup, they only exist on
+// Linux
+func cgroupMemory() (usage, limit uint64, ok bool) {
+	return 0, 0, false
+}
+
+// processMemory returns the memory the Go runtime obtained from the OS,
+// the closest portable measure of the process's resident memory
+func processMemory() (uint64, error) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.Sys, nil
+}
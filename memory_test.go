@@ -0,0 +1,27 @@
+package dexter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryPressure(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	used := make(chan uint64, 3)
+	used <- 500
+	used <- 950
+	dex.watchMemory(MemoryWatch{Limit: 1000, Threshold: 0.9, Interval: time.Millisecond}, func() (uint64, error) {
+		return <-used, nil
+	})
+
+	dex.WaitAndKill()
+	if reason := dex.LastReport().Reason; reason != "memory pressure" {
+		t.Errorf("shut down for %q", reason)
+	}
+}
+
+func TestProcessMemory(t *testing.T) {
+	if used, err := processMemory(); err != nil || used == 0 {
+		t.Errorf("process uses %d bytes, %v", used, err)
+	}
+}
@@ -0,0 +1,23 @@
+package dexter
+
+import "time"
+
+// Metrics receives measurements emitted by dexter while it shuts down.
+// Tags are "key:value" pairs, the same convention used by Datadog.
+//
+// The following metrics are emitted:
+//
+//	shutdown.duration     timing, whole kill sequence
+//	target.duration       timing, per target, tagged with target:<name>
+//	closer.errors         count, per target, tagged with target:<name>
+//	force_kill.triggered  count, emitted right before a forced exit
+type Metrics interface {
+	Timing(name string, value time.Duration, tags ...string)
+	Count(name string, value int64, tags ...string)
+}
+
+// nopMetrics is used when no metrics sink has been configured
+type nopMetrics struct{}
+
+func (nopMetrics) Timing(string, time.Duration, ...string) {}
+func (nopMetrics) Count(string, int64, ...string)          {}
@@ -0,0 +1,91 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// TrackCloserNamed tracks closer like TrackCloser under a human readable
+// name, such as "orders-db", used instead of its type in log lines,
+// release hooks and the errors returned by Close
+func (t *Target) TrackCloserNamed(name string, closer io.Closer) {
+	t.TrackCloser(&namedCloser{name: name, closer: closer})
+}
+
+// TrackChannelNamed tracks channel like TrackChannel under a human
+// readable name
+func (t *Target) TrackChannelNamed(name string, channel interface{}) error {
+	if err := t.TrackChannel(channel); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	if t.channelNames == nil {
+		t.channelNames = map[interface{}]string{}
+	}
+	t.channelNames[channel] = name
+	t.mu.Unlock()
+	return nil
+}
+
+// TrackCancelNamed tracks cancel like TrackCancel under a human readable
+// name, reported to release hooks
+func (t *Target) TrackCancelNamed(name string, cancel context.CancelFunc) {
+	t.trackFuncNamed(name, func() { cancel() })
+}
+
+// namedCloser gives a closer a name
+type namedCloser struct {
+	name   string
+	closer io.Closer
+}
+
+func (c *namedCloser) Close() error {
+	return c.CloseWithContext(context.Background())
+}
+
+// CloseWithContext passes ctx on to wrapped closers implementing
+// ContextCloser, errors are prefixed with the name
+func (c *namedCloser) CloseWithContext(ctx context.Context) error {
+	if err := closeContext(ctx, c.closer); err != nil {
+		return fmt.Errorf("%s: %w", c.name, err)
+	}
+	return nil
+}
+
+func (c *namedCloser) unwrap() interface{} {
+	return unwrap(c.closer)
+}
+
+func (c *namedCloser) resourceName() string {
+	return c.name
+}
+
+// ReportDetails passes through the details of the wrapped closer
+func (c *namedCloser) ReportDetails() map[string]string {
+	if detailer, ok := c.closer.(ReportDetailer); ok {
+		return detailer.ReportDetails()
+	}
+	return nil
+}
+
+// resourceName returns the name resource was tracked with, or its type
+func resourceName(resource interface{}) string {
+	if named, ok := resource.(interface{ resourceName() string }); ok {
+		return named.resourceName()
+	}
+	if w, ok := resource.(wrapper); ok {
+		return resourceName(w.unwrap())
+	}
+	return fmt.Sprintf("%T", resource)
+}
+
+// channelName returns the name channel was tracked with, or its type
+func (t *Target) channelName(channel interface{}) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if name, ok := t.channelNames[channel]; ok {
+		return name
+	}
+	return fmt.Sprintf("%T", channel)
+}
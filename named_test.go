@@ -0,0 +1,38 @@
+package dexter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNamedResources(t *testing.T) {
+	target := NewTarget("named")
+	var names []string
+	target.OnRelease(func(r Release) { names = append(names, r.Kind.String()+":"+r.Name) })
+	_, cancel := context.WithCancel(context.Background())
+	target.TrackCancelNamed("poller", cancel)
+	target.TrackCloserNamed("orders-db", closerFunc(func() error { return io.ErrUnexpectedEOF }))
+	target.TrackChannelNamed("jobs", make(chan int))
+	block := make(chan struct{})
+	defer close(block)
+	target.TrackCloser(WithTimeout(&namedCloser{name: "cache", closer: closerFunc(func() error {
+		<-block
+		return nil
+	})}, time.Millisecond))
+
+	errs := target.kill()
+	if len(errs) != 2 || !errors.Is(errs[0], io.ErrUnexpectedEOF) || !strings.HasPrefix(errs[0].Error(), "orders-db: ") {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if !strings.Contains(errs[1].Error(), "closing cache timed out") {
+		t.Errorf("timeout does not name the closer: %v", errs[1])
+	}
+	want := "func:poller closer:orders-db closer:cache channel:jobs"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("released %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,99 @@
+package dexter
+
+import "fmt"
+
+// NamePolicy decides what happens when a target is tracked under the name
+// of a target already tracked, which makes logs and name based APIs such
+// as KillAfter and the control socket ambiguous
+type NamePolicy int
+
+const (
+	// AllowDuplicateNames tracks the target as it is, Validate reports the
+	// duplicate.  It is the default.
+	AllowDuplicateNames NamePolicy = iota
+	// UniqueNames rejects the target with a *DuplicateNameError, returned
+	// by TrackBefore, TrackAfter, Replace and Adopt and the panic value of
+	// Track and TrackPhase
+	UniqueNames
+	// SuffixDuplicateNames renames the target by appending an index, the
+	// second "db" becomes "db#2", and the new name is used everywhere.
+	// Adopt rejects duplicates instead, like UniqueNames.
+	SuffixDuplicateNames
+)
+
+func (p NamePolicy) String() string {
+	switch p {
+	case AllowDuplicateNames:
+		return "allow"
+	case UniqueNames:
+		return "unique"
+	case SuffixDuplicateNames:
+		return "suffix"
+	}
+	return "unknown"
+}
+
+// WithNamePolicy sets what happens to targets tracked under a name already
+// taken, see NamePolicy
+func WithNamePolicy(policy NamePolicy) Option {
+	return func(d *Dexter) {
+		d.namePolicy = policy
+	}
+}
+
+// DuplicateNameError is returned for a target tracked under a name already
+// taken when names must be unique
+type DuplicateNameError struct {
+	Name string
+}
+
+func (e *DuplicateNameError) Error() string {
+	return fmt.Sprintf("dexter: a target named %s is already tracked", e.Name)
+}
+
+// admit applies the name policy to target before it is tracked, except is
+// a target about to be replaced whose name is free.  d.mu must be held.
+func (d *Dexter) admit(target, except *Target) error {
+	taken := func(name string) bool {
+		for _, other := range d.targets {
+			if other != target && other != except && other.name == name {
+				return true
+			}
+		}
+		return false
+	}
+	if d.namePolicy == AllowDuplicateNames || !taken(target.name) {
+		return nil
+	}
+	if d.namePolicy == UniqueNames {
+		return &DuplicateNameError{Name: target.name}
+	}
+	name := target.name
+	for i := 2; taken(name); i++ {
+		name = fmt.Sprintf("%s#%d", target.name, i)
+	}
+	d.logf("Target %s is already tracked, tracking the new one as %s\n", target.name, name)
+	target.mu.Lock()
+	target.name = name
+	target.mu.Unlock()
+	return nil
+}
+
+// admitAll checks the names of targets about to be adopted, which can't be
+// renamed, before any of them is tracked.  d.mu must be held.
+func (d *Dexter) admitAll(targets []*Target) error {
+	if d.namePolicy == AllowDuplicateNames {
+		return nil
+	}
+	taken := map[string]bool{}
+	for _, target := range d.targets {
+		taken[target.name] = true
+	}
+	for _, target := range targets {
+		if taken[target.name] {
+			return &DuplicateNameError{Name: target.name}
+		}
+		taken[target.name] = true
+	}
+	return nil
+}
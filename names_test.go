@@ -0,0 +1,68 @@
+package dexter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSuffixDuplicateNames(t *testing.T) {
+	dex := NewDexter(WithManualTrigger(), WithNamePolicy(SuffixDuplicateNames))
+	first, second, third := NewTarget("db"), NewTarget("db"), NewTarget("db")
+	dex.Track(first)
+	dex.Track(second)
+	if err := dex.TrackAfter("db", third); err != nil {
+		t.Fatal(err)
+	}
+	if second.Name() != "db#2" || third.Name() != "db#3" || first.Name() != "db" {
+		t.Errorf("named %s, %s, %s", first.Name(), second.Name(), third.Name())
+	}
+	dex.Track(first)
+	if first.Name() != "db" {
+		t.Errorf("tracking again renamed the target %s", first.Name())
+	}
+
+	dex.Close()
+	if name := dex.LastReport().Targets[2].Name; name != "db#2" {
+		t.Errorf("report names %s", name)
+	}
+}
+
+func TestUniqueNames(t *testing.T) {
+	dex := NewDexter(WithManualTrigger(), WithNamePolicy(UniqueNames))
+	dex.Track(NewTarget("db"))
+
+	var derr *DuplicateNameError
+	if err := dex.TrackBefore("db", NewTarget("db")); !errors.As(err, &derr) || derr.Name != "db" {
+		t.Errorf("got %v", err)
+	}
+	if _, err := dex.Replace("db", NewTarget("db")); err != nil {
+		t.Errorf("replacing a target under its own name: %v", err)
+	}
+	defer func() {
+		if _, ok := recover().(*DuplicateNameError); !ok {
+			t.Error("Track did not panic with a *DuplicateNameError")
+		}
+	}()
+	dex.Track(NewTarget("db"))
+}
+
+func TestAdoptDuplicateNames(t *testing.T) {
+	for _, policy := range []NamePolicy{UniqueNames, SuffixDuplicateNames} {
+		app := NewDexter(WithManualTrigger(), WithNamePolicy(policy))
+		app.Track(NewTarget("db"))
+		module := NewDexter(WithManualTrigger())
+		module.Track(NewTarget("cache"))
+		module.Track(NewTarget("db"))
+
+		var derr *DuplicateNameError
+		if err := app.Adopt(module); !errors.As(err, &derr) || derr.Name != "db" {
+			t.Errorf("%v: got %v", policy, err)
+		}
+		if n, m := len(app.Targets()), len(module.Targets()); n != 1 || m != 2 {
+			t.Errorf("%v: adoption left %d and %d targets, want 1 and 2", policy, n, m)
+		}
+		if name := module.Targets()[1].Name(); name != "db" {
+			t.Errorf("%v: target renamed to %s", policy, name)
+		}
+	}
+}
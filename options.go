@@ -0,0 +1,77 @@
+package dexter
+
+import (
+	"fmt"
+	"time"
+)
+
+// Option configures a Dexter, options are passed to NewDexter
+type Option func(*Dexter)
+
+// lastRitesBudget bounds how long last rites may delay a forced exit
+const lastRitesBudget = 500 * time.Millisecond
+
+// WithLastRites registers fn to run right before the process is force
+// exited, e.g. to fsync a journal or remove a PID file.  Last rites run in
+// the order they were registered and share a small time budget, whatever
+// hasn't finished by then is cut short by the exit.
+func WithLastRites(fn func()) Option {
+	return func(d *Dexter) {
+		d.lastRites = append(d.lastRites, fn)
+	}
+}
+
+// runLastRites runs fns one after the other, giving up after budget
+func runLastRites(fns []func(), budget time.Duration) {
+	if len(fns) == 0 {
+		return
+	}
+	done := make(chan struct{})
+	go labelled("", "last-rites", func() {
+		defer close(done)
+		for _, fn := range fns {
+			fn()
+		}
+	})
+
+	select {
+	case <-done:
+	case <-time.After(budget):
+		dlog.Println("Last rites did not finish in time")
+	}
+}
+
+// WithNoForceExit keeps dexter from ever exiting the process, for
+// frameworks embedding it.  Once the force kill window expires the target
+// being killed and every target after it are abandoned instead: they are
+// left to finish in the background, reported with a *ForcedAbandonError
+// and WaitAndKill returns.  Last rites don't run, OverrunExit targets are
+// abandoned like OverrunSkip ones.
+func WithNoForceExit() Option {
+	return func(d *Dexter) {
+		d.noForceExit = true
+	}
+}
+
+// ForcedAbandonError is reported for the targets abandoned because the
+// force kill window expired with WithNoForceExit
+type ForcedAbandonError struct {
+	Target string
+	Window time.Duration
+}
+
+func (e *ForcedAbandonError) Error() string {
+	return fmt.Sprintf("dexter: abandoned target %s, force kill window of %v expired", e.Target, e.Window)
+}
+
+// forceAbandon is forceKill for WithNoForceExit, it keeps the process
+// alive and can run once per shutdown.  Abandoned targets keep their locks,
+// they may still be using what the locks guard, the locks are released
+// once they drain.
+func (d *Dexter) forceAbandon() {
+	d.logf("Timeout! - abandoning remaining targets\n")
+	d.metrics.Count("force_kill.abandoned", 1)
+	if d.profileDir != "" {
+		captureProfiles(d.profileDir, profileCaptureTimeout)
+	}
+}
@@ -0,0 +1,70 @@
+package dexter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLastRitesBeforeForcedExit(t *testing.T) {
+	var order []string
+	dex := NewDexter(
+		WithManualTrigger(),
+		WithLastRites(func() { order = append(order, "journal") }),
+		WithLastRites(func() { order = append(order, "pidfile") }),
+	)
+	dex.exitFunc = func(int) { order = append(order, "exit") }
+
+	dex.forceKill()
+	dex.forceKill()
+
+	if len(order) != 3 || order[0] != "journal" || order[1] != "pidfile" || order[2] != "exit" {
+		t.Errorf("unexpected order %v", order)
+	}
+}
+
+func TestLastRitesBudget(t *testing.T) {
+	start := time.Now()
+	runLastRites([]func(){func() { time.Sleep(time.Second) }}, 10*time.Millisecond)
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("last rites were not cut short")
+	}
+}
+
+func TestNoForceExit(t *testing.T) {
+	dex := NewDexter(WithManualTrigger(), WithNoForceExit())
+	dex.SetForceKillInterval(20 * time.Millisecond)
+	dex.exitFunc = func(int) { t.Error("exited with WithNoForceExit") }
+	stuck, release := stuckTarget("stuck")
+	defer release()
+	stuck.SetDeadline(time.Second, OverrunExit)
+	lock := filepath.Join(t.TempDir(), "lock")
+	if err := ioutil.WriteFile(lock, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stuck.TrackLock(LockFile(lock))
+	after := NewTarget("after")
+	dex.Track(stuck)
+	dex.Track(after)
+
+	dex.Shutdown("test")
+	dex.WaitAndKill()
+
+	report := dex.LastReport()
+	if report.Clean() || len(report.Targets) != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	for _, tr := range report.Targets {
+		if len(tr.Errors) != 1 {
+			t.Fatalf("%s: unexpected errors %v", tr.Name, tr.Errors)
+		}
+		if _, ok := tr.Errors[0].(*ForcedAbandonError); !ok {
+			t.Errorf("%s: unexpected error %v", tr.Name, tr.Errors[0])
+		}
+	}
+	if _, err := os.Stat(lock); err != nil {
+		t.Errorf("abandoned target lost its lock: %v", err)
+	}
+}
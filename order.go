@@ -0,0 +1,200 @@
+package dexter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TrackBefore adds target to the kill list right before the target called
+// name, in the same phase, so it is killed just ahead of it
+func (d *Dexter) TrackBefore(name string, target *Target) error {
+	return d.trackAt(name, 0, target)
+}
+
+// TrackAfter adds target to the kill list right after the target called
+// name, in the same phase, so it is killed just after it
+func (d *Dexter) TrackAfter(name string, target *Target) error {
+	return d.trackAt(name, 1, target)
+}
+
+// Replace swaps the target called name for target, keeping its position and
+// phase in the kill order.  The replaced target is returned so the caller
+// can kill it, dexter no longer tracks it nor shares its closers.
+func (d *Dexter) Replace(name string, target *Target) (old *Target, err error) {
+	defer func() {
+		if err == nil {
+			d.warnOverBudget(old, target)
+		}
+	}()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	i := d.indexOf(name)
+	if i < 0 {
+		return nil, fmt.Errorf("no target named %q is tracked", name)
+	}
+
+	old = d.targets[i]
+	if err := d.admit(target, old); err != nil {
+		return nil, err
+	}
+	target.mu.Lock()
+	target.phase = old.Phase()
+	target.mu.Unlock()
+	d.targets[i] = target
+	target.join(d.shared)
+	old.unshare()
+	return old, nil
+}
+
+func (d *Dexter) trackAt(name string, offset int, target *Target) (err error) {
+	defer func() {
+		if err == nil {
+			d.warnOverBudget(nil, target)
+		}
+	}()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	i := d.indexOf(name)
+	if i < 0 {
+		return fmt.Errorf("no target named %q is tracked", name)
+	}
+
+	if err := d.admit(target, nil); err != nil {
+		return err
+	}
+	phase := d.targets[i].Phase()
+	target.mu.Lock()
+	target.phase = phase
+	target.mu.Unlock()
+
+	i += offset
+	d.targets = append(d.targets, nil)
+	copy(d.targets[i+1:], d.targets[i:])
+	d.targets[i] = target
+	target.join(d.shared)
+	return nil
+}
+
+// indexOf returns the position of the first target called name, d.mu
+// must be held
+func (d *Dexter) indexOf(name string) int {
+	for i, target := range d.targets {
+		if target.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// orderConstraint requires the targets called later to be killed after
+// the ones called earlier
+type orderConstraint struct {
+	earlier, later string
+}
+
+// CycleError is returned when an ordering constraint contradicts the
+// constraints declared before it, Cycle lists the target names along the
+// cycle, starting and ending with the same name
+type CycleError struct {
+	Cycle []string
+}
+
+func (e *CycleError) Error() string {
+	return "dexter: ordering cycle: " + strings.Join(e.Cycle, " -> ")
+}
+
+// KillAfter declares that the targets called later must be killed after
+// the ones called earlier, e.g. KillAfter("storage", "workers").  The
+// constraint takes precedence over phases, tag order and the order targets
+// were tracked in, which decide everything it leaves open.  Names which
+// are not tracked when shutdown starts are ignored.  A constraint which
+// would make the order impossible is rejected with a *CycleError.
+func (d *Dexter) KillAfter(later, earlier string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if path := constraintPath(d.constraints, later, earlier, map[string]bool{}); path != nil {
+		return &CycleError{Cycle: append([]string{earlier}, path...)}
+	}
+	d.constraints = append(d.constraints, orderConstraint{earlier: earlier, later: later})
+	return nil
+}
+
+// KillBefore declares that the targets called earlier must be killed
+// before the ones called later, see KillAfter
+func (d *Dexter) KillBefore(earlier, later string) error {
+	return d.KillAfter(later, earlier)
+}
+
+// constrainAll adds constraints as KillAfter would, when one of them makes
+// the order impossible none is added.  d.mu must be held.
+func (d *Dexter) constrainAll(constraints []orderConstraint) error {
+	added := d.constraints[:len(d.constraints):len(d.constraints)]
+	for _, c := range constraints {
+		if path := constraintPath(added, c.later, c.earlier, map[string]bool{}); path != nil {
+			return &CycleError{Cycle: append([]string{c.earlier}, path...)}
+		}
+		added = append(added, c)
+	}
+	d.constraints = added
+	return nil
+}
+
+// constraintPath returns the names along a chain of constraints leading
+// from from to to, nil if there is none
+func constraintPath(constraints []orderConstraint, from, to string, seen map[string]bool) []string {
+	if from == to {
+		return []string{to}
+	}
+	if seen[from] {
+		return nil
+	}
+	seen[from] = true
+	for _, c := range constraints {
+		if c.earlier != from {
+			continue
+		}
+		if path := constraintPath(constraints, c.later, to, seen); path != nil {
+			return append([]string{from}, path...)
+		}
+	}
+	return nil
+}
+
+// constrain reorders targets to satisfy constraints, otherwise keeping
+// their order: each step kills the first target which has nothing left
+// to wait for
+func constrain(targets []*Target, constraints []orderConstraint) []*Target {
+	if len(constraints) == 0 {
+		return targets
+	}
+	waitsFor := func(target *Target, remaining []*Target) bool {
+		for _, c := range constraints {
+			if c.later != target.name {
+				continue
+			}
+			for _, other := range remaining {
+				if other != target && other.name == c.earlier {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	ordered := make([]*Target, 0, len(targets))
+	remaining := append([]*Target(nil), targets...)
+	for len(remaining) > 0 {
+		// constraints are acyclic, so the fallback only guards against
+		// several targets sharing a name
+		next := 0
+		for i, target := range remaining {
+			if !waitsFor(target, remaining) {
+				next = i
+				break
+			}
+		}
+		ordered = append(ordered, remaining[next])
+		remaining = append(remaining[:next], remaining[next+1:]...)
+	}
+	return ordered
+}
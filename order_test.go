@@ -0,0 +1,89 @@
+package dexter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrackBeforeAfter(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.Track(NewTarget("http"))
+	dex.TrackPhase(PhaseStorage, NewTarget("db"))
+
+	if err := dex.TrackBefore("http", NewTarget("lb")); err != nil {
+		t.Fatal(err)
+	}
+	if err := dex.TrackAfter("db", NewTarget("cache")); err != nil {
+		t.Fatal(err)
+	}
+	if err := dex.TrackAfter("missing", NewTarget("plugin")); err == nil {
+		t.Error("expected an error for an unknown target")
+	}
+
+	want := []string{"lb", "http", "db", "cache"}
+	for i, target := range dex.killOrder() {
+		if target.Name() != want[i] {
+			t.Fatalf("position %d is %s, want %s", i, target.Name(), want[i])
+		}
+	}
+}
+
+func TestReplace(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	old := NewTarget("listener")
+	dex.TrackPhase(PhaseIngress, old)
+	dex.Track(NewTarget("workers"))
+
+	fresh := NewTarget("listener")
+	replaced, err := dex.Replace("listener", fresh)
+	if err != nil || replaced != old {
+		t.Fatalf("replace returned %v, %v", replaced, err)
+	}
+
+	order := dex.killOrder()
+	if len(order) != 2 || order[0] != fresh || fresh.Phase() != PhaseIngress {
+		t.Errorf("replacement did not take the old target's place")
+	}
+	if _, err := dex.Replace("missing", NewTarget("x")); err == nil {
+		t.Error("expected an error for an unknown target")
+	}
+}
+
+func TestKillAfter(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	for _, name := range []string{"storage", "workers", "cache"} {
+		dex.Track(NewTarget(name))
+	}
+	if err := dex.KillAfter("storage", "workers"); err != nil {
+		t.Fatal(err)
+	}
+	if err := dex.KillBefore("cache", "storage"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, target := range dex.killOrder() {
+		got = append(got, target.Name())
+	}
+	if strings.Join(got, " ") != "workers cache storage" {
+		t.Errorf("kill order %v", got)
+	}
+}
+
+func TestKillAfterCycle(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.KillAfter("b", "a")
+	dex.KillAfter("c", "b")
+
+	err := dex.KillAfter("a", "c")
+	cycle, ok := err.(*CycleError)
+	if !ok {
+		t.Fatalf("expected a *CycleError, got %v", err)
+	}
+	if strings.Join(cycle.Cycle, " ") != "c a b c" {
+		t.Errorf("unexpected cycle %v", cycle.Cycle)
+	}
+	if len(dex.constraints) != 2 {
+		t.Error("contradicting constraint was recorded")
+	}
+}
@@ -0,0 +1,80 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PhaseOutbox runs after PhaseFlush and before PhaseStorage, so outbox and
+// WAL style buffers can still reach the storage they are flushed to
+const PhaseOutbox Phase = 350
+
+// Outbox is a buffer of records which have to reach durable storage before
+// the process exits
+type Outbox interface {
+	// Flush writes out as many buffered records as it can and returns how
+	// many are left unflushed
+	Flush(ctx context.Context) (remaining int, err error)
+}
+
+// UnflushedError is reported when an outbox still holds records after its
+// last flush attempt
+type UnflushedError struct {
+	Records int
+	Err     error
+}
+
+func (e *UnflushedError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%d records could not be flushed", e.Records)
+	}
+	return fmt.Sprintf("%d records could not be flushed: %v", e.Records, e.Err)
+}
+
+func (e *UnflushedError) Unwrap() error {
+	return e.Err
+}
+
+// TrackOutbox tracks a target called name in PhaseOutbox which flushes
+// outbox, retrying up to retries times with backoff between attempts.  The
+// target is returned so a deadline can be set on it.
+func (d *Dexter) TrackOutbox(name string, outbox Outbox, retries int, backoff time.Duration) *Target {
+	target := NewTarget(name)
+	target.TrackCloser(outboxCloser{outbox: outbox, retries: retries, backoff: backoff})
+	d.TrackPhase(PhaseOutbox, target)
+	return target
+}
+
+type outboxCloser struct {
+	outbox  Outbox
+	retries int
+	backoff time.Duration
+}
+
+func (o outboxCloser) Close() error {
+	return o.CloseWithContext(context.Background())
+}
+
+// CloseWithContext flushes with ctx and gives up retrying once it is done
+func (o outboxCloser) CloseWithContext(ctx context.Context) error {
+	var remaining int
+	var err error
+	for attempt := 0; attempt <= o.retries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(o.backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return &UnflushedError{Records: remaining, Err: err}
+			case <-timer.C:
+			}
+		}
+		remaining, err = o.outbox.Flush(ctx)
+		if remaining == 0 && err == nil {
+			return nil
+		}
+		dlog.Printf("Outbox flush attempt %d left %d records: %v\n", attempt+1, remaining, err)
+	}
+	return &UnflushedError{Records: remaining, Err: err}
+}
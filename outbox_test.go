@@ -0,0 +1,62 @@
+package dexter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+// flakyOutbox flushes one record per call and fails while records remain
+type flakyOutbox struct {
+	records int
+}
+
+func (o *flakyOutbox) Flush(context.Context) (int, error) {
+	if o.records > 0 {
+		o.records--
+	}
+	if o.records > 0 {
+		return o.records, errors.New("broker unavailable")
+	}
+	return 0, nil
+}
+
+func TestTrackOutbox(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.TrackPhase(PhaseStorage, NewTarget("db"))
+	dex.TrackOutbox("recovers", &flakyOutbox{records: 3}, 2, 0)
+	dex.TrackOutbox("gives-up", &flakyOutbox{records: 5}, 2, 0)
+
+	dex.SimulateSignal(os.Interrupt)
+	dex.WaitAndKill()
+
+	report := dex.LastReport()
+	if report.Targets[2].Name != "db" {
+		t.Error("outbox phase ran after storage")
+	}
+	if len(report.Targets[0].Errors) != 0 {
+		t.Errorf("unexpected errors %v", report.Targets[0].Errors)
+	}
+	errs := report.Targets[1].Errors
+	if len(errs) != 1 || errs[0].Error() != "2 records could not be flushed: broker unavailable" {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestOutboxBackoffHonoursContext(t *testing.T) {
+	outbox := &flakyOutbox{records: 5}
+	closer := outboxCloser{outbox: outbox, retries: 3, backoff: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	var unflushed *UnflushedError
+	if err := closer.CloseWithContext(ctx); !errors.As(err, &unflushed) || unflushed.Records != 4 {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("backed off %v past the context", elapsed)
+	}
+}
@@ -0,0 +1,129 @@
+package dexter
+
+import (
+	"os/signal"
+	"sync"
+)
+
+// signalOwner is the root Dexter, the only one receiving OS signals
+var signalOwner struct {
+	sync.Mutex
+	dex *Dexter
+}
+
+// SignalOwnerError is the panic value of NewDexter when another Dexter
+// already owns the OS signals.  Only one root Dexter may listen for
+// signals, others have to be created with WithManualTrigger or Child.
+type SignalOwnerError struct{}
+
+func (e *SignalOwnerError) Error() string {
+	return "dexter: another Dexter already owns the OS signals, use WithManualTrigger or Child"
+}
+
+// WithManualTrigger makes a Dexter which doesn't listen for OS signals,
+// its shutdown is only started with Shutdown, SimulateSignal or by its
+// parent.  Any number of them may exist next to the root Dexter.
+func WithManualTrigger() Option {
+	return func(d *Dexter) {
+		d.manual = true
+	}
+}
+
+// Child returns a Dexter which doesn't listen for OS signals and starts
+// shutting down as soon as d does, so libraries can run their own kill
+// order under the application's root Dexter.  The child's WaitAndKill
+// still has to be called.
+func (d *Dexter) Child(opts ...Option) *Dexter {
+	child := NewDexter(append(opts, WithManualTrigger())...)
+	parent, stopping := d.Stopping(), child.Stopping()
+	go func() {
+		select {
+		case <-parent:
+			child.Shutdown("parent stopping")
+		case <-stopping:
+		}
+	}()
+	return child
+}
+
+// ownSignals makes d the root Dexter and relays the shutdown signals to
+// it, it panics with a *SignalOwnerError if there already is one
+func (d *Dexter) ownSignals() {
+	signalOwner.Lock()
+	defer signalOwner.Unlock()
+	if signalOwner.dex != nil {
+		panic(&SignalOwnerError{})
+	}
+	signalOwner.dex = d
+	// signal.Notify without any signals would relay every signal
+	if len(d.signals) > 0 {
+		signal.Notify(d.waiter, d.signals...)
+	}
+	d.notifyExitSignal()
+}
+
+// ReleaseSignals stops relaying OS signals to d, another root Dexter may
+// be created afterwards.  WaitAndKill releases them once it returns.
+func (d *Dexter) ReleaseSignals() {
+	signalOwner.Lock()
+	defer signalOwner.Unlock()
+	if signalOwner.dex != d {
+		return
+	}
+	signal.Stop(d.waiter)
+	if d.exits != nil {
+		signal.Stop(d.exits)
+	}
+	if d.reloads != nil {
+		signal.Stop(d.reloads)
+	}
+	signalOwner.dex = nil
+}
+
+// Adopt moves other's targets to the end of d's kill list, keeping their
+// phases, relative order and ordering constraints, and disarms other's
+// signal handling.  It is meant for composing an application from modules
+// which each built their own Dexter.  other is left without targets.
+// Adopted targets are already in use so they are never renamed, unless d
+// allows duplicate names a name clashing with d's targets is rejected with
+// a *DuplicateNameError and both Dexters are left as they were.  So is an
+// ordering constraint of other contradicting d's, with a *CycleError.
+func (d *Dexter) Adopt(other *Dexter) error {
+	if other == d {
+		return nil
+	}
+	var adopted []*Target
+	defer func() {
+		if len(adopted) > 0 {
+			d.warnOverBudget(nil, adopted...)
+		}
+	}()
+	// a.Adopt(b) and b.Adopt(a) would otherwise lock the two Dexters in
+	// opposite orders
+	dexterAdoptions.Lock()
+	defer dexterAdoptions.Unlock()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	other.mu.Lock()
+	defer other.mu.Unlock()
+	if err := d.admitAll(other.targets); err != nil {
+		return err
+	}
+	if err := d.constrainAll(other.constraints); err != nil {
+		return err
+	}
+
+	adopted = other.targets
+	for _, target := range adopted {
+		target.join(d.shared)
+	}
+	d.targets = append(d.targets, adopted...)
+	other.targets, other.constraints = []*Target{}, nil
+	other.manual = true
+	other.ReleaseSignals()
+	return nil
+}
+
+// dexterAdoptions serializes Dexter.Adopt, the only place holding the locks
+// of two Dexters
+var dexterAdoptions sync.Mutex
@@ -0,0 +1,122 @@
+package dexter
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSingleSignalOwner(t *testing.T) {
+	root := NewDexter()
+	defer root.ReleaseSignals()
+
+	func() {
+		defer func() {
+			if _, ok := recover().(*SignalOwnerError); !ok {
+				t.Error("second root did not panic with a *SignalOwnerError")
+			}
+		}()
+		NewDexter()
+	}()
+	NewDexter(WithManualTrigger())
+
+	root.ReleaseSignals()
+	NewDexter().ReleaseSignals()
+}
+
+func TestChild(t *testing.T) {
+	parent := NewDexter(WithManualTrigger())
+	child := parent.Child()
+	target := NewTarget("library")
+	child.Track(target)
+
+	parent.Shutdown("test")
+	go parent.WaitAndKill()
+	done := make(chan struct{})
+	go func() {
+		child.WaitAndKill()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("child did not shut down with its parent")
+	}
+	if target.State() != TargetStopped {
+		t.Errorf("child's target is %v", target.State())
+	}
+}
+
+func TestDexterAdopt(t *testing.T) {
+	app := NewDexter(WithManualTrigger())
+	app.Track(NewTarget("http"))
+	module := NewDexter()
+	defer module.ReleaseSignals()
+	module.TrackPhase(PhaseStorage, NewTarget("db"))
+	module.Track(NewTarget("worker"))
+
+	app.Adopt(module)
+
+	var got []string
+	for _, target := range app.Targets() {
+		got = append(got, target.Name())
+	}
+	if len(got) != 3 || got[0] != "http" || got[1] != "worker" || got[2] != "db" {
+		t.Errorf("unexpected kill order %v", got)
+	}
+	if len(module.Targets()) != 0 {
+		t.Error("adopted targets still tracked by the module")
+	}
+	// the module gave up the signals
+	NewDexter().ReleaseSignals()
+}
+
+func TestDexterAdoptCycle(t *testing.T) {
+	app := NewDexter(WithManualTrigger())
+	app.Track(NewTarget("http"))
+	app.Track(NewTarget("db"))
+	if err := app.KillAfter("db", "http"); err != nil {
+		t.Fatal(err)
+	}
+	module := NewDexter(WithManualTrigger())
+	module.Track(NewTarget("worker"))
+	module.KillAfter("worker", "db")
+	module.KillAfter("http", "worker")
+
+	if _, ok := app.Adopt(module).(*CycleError); !ok {
+		t.Fatal("contradicting constraints were adopted")
+	}
+	if len(app.constraints) != 1 || len(module.Targets()) != 1 {
+		t.Errorf("failed adoption changed the dexters")
+	}
+}
+
+func TestDexterAdoptEachOther(t *testing.T) {
+	a, b := NewDexter(WithManualTrigger()), NewDexter(WithManualTrigger())
+	a.Track(NewTarget("a"))
+	b.Track(NewTarget("b"))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			a.Adopt(b)
+		}()
+		go func() {
+			defer wg.Done()
+			b.Adopt(a)
+		}()
+		wg.Wait()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("adopting each other deadlocked")
+	}
+	if n := len(a.Targets()) + len(b.Targets()); n != 2 {
+		t.Errorf("%d targets left, want 2", n)
+	}
+}
@@ -0,0 +1,117 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicPolicy decides what happens when one of a target's closers or funcs
+// panics while the target is killed
+type PanicPolicy int
+
+const (
+	// RecoverPanic recovers the panic and records it as a *PanicError
+	// among the target's errors, shutdown goes on.  It is the default.
+	RecoverPanic PanicPolicy = iota
+	// RepanicAfterShutdown records the panic like RecoverPanic and raises
+	// it again once the whole shutdown completed, so the process still
+	// crashes on the bug but every other target was killed first.
+	RepanicAfterShutdown
+	// ExitOnPanic force exits right away as an expired force kill window
+	// would, or re-raises the panic when there is no force exit, e.g. for
+	// Target.Kill or with WithNoForceExit.
+	ExitOnPanic
+)
+
+func (p PanicPolicy) String() string {
+	switch p {
+	case RecoverPanic:
+		return "recover"
+	case RepanicAfterShutdown:
+		return "repanic"
+	case ExitOnPanic:
+		return "exit"
+	}
+	return "unknown"
+}
+
+// PanicError is a panic recovered from a closer or func of a target
+type PanicError struct {
+	Target   string
+	Resource string
+	Value    interface{}
+	Stack    []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("dexter: target %s: panic in %s: %v", e.Target, e.Resource, e.Value)
+}
+
+// SetPanicPolicy sets what happens when the target's closers or funcs
+// panic, see PanicPolicy
+func (t *Target) SetPanicPolicy(policy PanicPolicy) {
+	t.mu.Lock()
+	t.panicPolicy = policy
+	t.mu.Unlock()
+}
+
+// PanicPolicy returns the target's panic policy
+func (t *Target) PanicPolicy() PanicPolicy {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.panicPolicy
+}
+
+// exitKey is the context key of the force exit ExitOnPanic calls
+type exitKey struct{}
+
+// withExit returns ctx carrying exit, nil when there is no force exit
+func withExit(ctx context.Context, exit func()) context.Context {
+	if exit == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, exitKey{}, exit)
+}
+
+// guard runs fn, releasing resource, and handles its panic according to
+// the target's panic policy
+func (t *Target) guard(ctx context.Context, resource string, fn func() error) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		perr := &PanicError{Target: t.name, Resource: resource, Value: r, Stack: debug.Stack()}
+		dlog.Printf("%v\n%s", perr, perr.Stack)
+		switch t.PanicPolicy() {
+		case RepanicAfterShutdown:
+			t.mu.Lock()
+			t.panics = append(t.panics, perr)
+			t.mu.Unlock()
+		case ExitOnPanic:
+			exit, _ := ctx.Value(exitKey{}).(func())
+			if exit == nil {
+				panic(perr)
+			}
+			exit()
+		}
+		err = perr
+	}()
+	return fn()
+}
+
+// repanic raises the first panic held back by RepanicAfterShutdown, on
+// the target or a target it adopted
+func (t *Target) repanic() {
+	t.mu.Lock()
+	panics, adopted := t.panics, t.adopted
+	t.panics = nil
+	t.mu.Unlock()
+	if len(panics) > 0 {
+		panic(panics[0])
+	}
+	for _, other := range adopted {
+		other.repanic()
+	}
+}
@@ -0,0 +1,78 @@
+package dexter
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPanicRecovered(t *testing.T) {
+	target := NewTarget("db")
+	closed := false
+	target.TrackCloser(closerFunc(func() error { panic("nil map") }))
+	target.TrackCloser(closerFunc(func() error {
+		closed = true
+		return nil
+	}))
+
+	var kerr *KillError
+	if err := target.Close(); !errors.As(err, &kerr) {
+		t.Fatalf("got %v", err)
+	}
+	var perr *PanicError
+	if !errors.As(kerr.Errs[0], &perr) || perr.Value != "nil map" || perr.Target != "db" {
+		t.Errorf("got %v", kerr.Errs)
+	}
+	if !closed {
+		t.Error("closer after the panic left open")
+	}
+}
+
+func TestPanicRepanicAfterShutdown(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	first := NewTarget("first")
+	first.SetPanicPolicy(RepanicAfterShutdown)
+	first.TrackCancel(func() { panic("bug") })
+	second := NewTarget("second")
+	closed := false
+	second.TrackCloser(closerFunc(func() error {
+		closed = true
+		return nil
+	}))
+	dex.Track(first)
+	dex.Track(second)
+
+	defer func() {
+		perr, ok := recover().(*PanicError)
+		if !ok || perr.Value != "bug" {
+			t.Errorf("recovered %v", perr)
+		}
+		if !closed {
+			t.Error("panic raised before the shutdown completed")
+		}
+	}()
+	dex.Shutdown("test")
+	dex.WaitAndKill()
+	t.Error("shutdown did not panic")
+}
+
+func TestPanicExit(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.SetForceKillInterval(time.Minute)
+	exited := make(chan int, 1)
+	dex.exitFunc = func(code int) { exited <- code }
+	target := NewTarget("db")
+	target.SetPanicPolicy(ExitOnPanic)
+	target.TrackCloser(closerFunc(func() error { panic("bug") }))
+	dex.Track(target)
+
+	dex.Close()
+	select {
+	case code := <-exited:
+		if code != 1 {
+			t.Errorf("exited with %d", code)
+		}
+	default:
+		t.Error("panic did not force exit")
+	}
+}
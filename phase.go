@@ -0,0 +1,94 @@
+package dexter
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Phase groups targets into a stage of the shutdown, phases are killed in
+// ascending order and targets within a phase in the order they were
+// tracked.  The gaps between the standard phases leave room for custom ones.
+type Phase int
+
+const (
+	// PhaseIngress is for whatever brings work in: listeners, consumers
+	PhaseIngress Phase = 100
+	// PhaseWorkers is for whatever processes work, Track uses this phase
+	PhaseWorkers Phase = 200
+	// PhaseFlush is for buffers which have to be written out
+	PhaseFlush Phase = 300
+	// PhaseStorage is for databases, caches and files
+	PhaseStorage Phase = 400
+	// PhaseTelemetry is for metrics, tracing and logging
+	PhaseTelemetry Phase = 500
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseLeadership:
+		return "leadership"
+	case PhaseIngress:
+		return "ingress"
+	case PhaseWorkers:
+		return "workers"
+	case PhaseFlush:
+		return "flush"
+	case PhaseOutbox:
+		return "outbox"
+	case PhaseStorage:
+		return "storage"
+	case PhaseTelemetry:
+		return "telemetry"
+	}
+	return fmt.Sprintf("phase(%d)", int(p))
+}
+
+// TrackPhase adds target to the kill list as part of phase, so packages
+// can register their targets without main() tracking them in exactly the
+// right order.  It panics with a *DuplicateNameError when the target's
+// name is taken under UniqueNames, see WithNamePolicy.
+func (d *Dexter) TrackPhase(phase Phase, target *Target) {
+	target.mu.Lock()
+	target.phase = phase
+	target.mu.Unlock()
+
+	d.mu.Lock()
+	if err := d.admit(target, nil); err != nil {
+		d.mu.Unlock()
+		panic(err)
+	}
+	d.targets = append(d.targets, target)
+	d.mu.Unlock()
+	target.join(d.shared)
+	d.warnOverBudget(nil, target)
+}
+
+// Phase returns the phase the target was tracked in
+func (t *Target) Phase() Phase {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.phase
+}
+
+// Targets returns the tracked targets in the order a graceful shutdown
+// would kill them
+func (d *Dexter) Targets() []*Target {
+	return d.killOrder()
+}
+
+// killOrder returns the tracked targets in the order they are killed
+func (d *Dexter) killOrder() []*Target {
+	d.mu.Lock()
+	targets := append([]*Target(nil), d.targets...)
+	tagOrder, constraints := d.tagOrder, d.constraints
+	d.mu.Unlock()
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		pi, pj := targets[i].Phase(), targets[j].Phase()
+		if pi != pj {
+			return pi < pj
+		}
+		return tagRank(tagOrder, targets[i]) < tagRank(tagOrder, targets[j])
+	})
+	return constrain(targets, constraints)
+}
@@ -0,0 +1,24 @@
+package dexter
+
+import "testing"
+
+func TestKillOrderByPhase(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	storage := NewTarget("storage")
+	workers1 := NewTarget("workers1")
+	ingress := NewTarget("ingress")
+	workers2 := NewTarget("workers2")
+
+	dex.TrackPhase(PhaseStorage, storage)
+	dex.Track(workers1)
+	dex.TrackPhase(PhaseIngress, ingress)
+	dex.Track(workers2)
+
+	want := []*Target{ingress, workers1, workers2, storage}
+	got := dex.killOrder()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("position %d is %s, want %s", i, got[i].Name(), want[i].Name())
+		}
+	}
+}
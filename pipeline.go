@@ -0,0 +1,56 @@
+package dexter
+
+import (
+	"context"
+	"time"
+)
+
+// WithPipeline shuts the targets down as a staged channel pipeline: only
+// the first target's intake, its funcs and channels, is closed, then each
+// target in kill order is waited for to drain before the intake of the
+// next one is closed, unless its producer closed it already.  Closers are
+// only closed once the whole pipeline drained, so every stage can still
+// use its connections while work flows through.  A target which doesn't
+// drain within its deadline holds the pipeline no longer.
+func WithPipeline() Option {
+	return func(d *Dexter) {
+		d.pipeline = true
+	}
+}
+
+// drainPipeline closes the intakes of targets one after the other, each
+// once the previous one drained.  exit is the force exit, if any.
+func (d *Dexter) drainPipeline(targets []*Target, skip func(*Target) bool, exit func()) {
+	ctx := withExit(context.Background(), exit)
+	for _, target := range targets {
+		if skip(target) {
+			continue
+		}
+		var errs []error
+		for _, err := range target.closeIntake(ctx) {
+			// a stage usually closes its output, the next stage's intake
+			if err != ErrChannelClosed {
+				errs = append(errs, err)
+			}
+		}
+		target.mu.Lock()
+		target.intakeErrs = errs
+		target.mu.Unlock()
+
+		drained := make(chan struct{})
+		go labelled(target.name, "pipeline-drain", func() {
+			target.wg.Wait()
+			close(drained)
+		})
+		var expired <-chan time.Time
+		if lim := d.limitFor(target); lim.deadline > 0 {
+			expired = time.After(lim.deadline)
+		}
+		select {
+		case <-drained:
+			target.logf("Pipeline stage %s drained\n", target.label())
+		case <-expired:
+			target.logf("Pipeline stage %s did not drain in time, moving on\n", target.label())
+		}
+	}
+}
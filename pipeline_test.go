@@ -0,0 +1,44 @@
+package dexter
+
+import "testing"
+
+func TestPipeline(t *testing.T) {
+	dex := NewDexter(WithManualTrigger(), WithPipeline())
+	parse, store := NewTarget("parse"), NewTarget("store")
+	in, parsed := make(chan int, 10), make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		in <- i
+	}
+	parse.TrackChannel(in)
+	store.TrackChannel(parsed)
+	db := &countingCloser{}
+	parse.TrackCloser(db)
+	store.TrackCloser(db)
+
+	parse.Go(func() error {
+		defer close(parsed)
+		for n := range in {
+			parsed <- n
+		}
+		return nil
+	})
+	stored := 0
+	store.Go(func() error {
+		for range parsed {
+			if db.closes > 0 {
+				t.Error("database closed while the pipeline was draining")
+			}
+			stored++
+		}
+		return nil
+	})
+	dex.Track(parse)
+	dex.Track(store)
+
+	if err := dex.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if stored != 10 || db.closes != 1 {
+		t.Errorf("stored %d, closed the database %d times", stored, db.closes)
+	}
+}
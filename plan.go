@@ -0,0 +1,108 @@
+package dexter
+
+import (
+	"os"
+	"os/signal"
+)
+
+// KillPlan selects which tracked targets a shutdown kills.  Skip reports
+// whether the plan leaves a target out, a nil Skip kills every target.
+//
+// A fast plan for Ctrl-C which leaves out expensive flushes:
+//
+//	dex.BindSignalPlan(os.Interrupt, dexter.KillPlan{
+//		Name: "fast",
+//		Skip: func(t *dexter.Target) bool { return t.Phase() == dexter.PhaseFlush },
+//	})
+type KillPlan struct {
+	Name string
+	Skip func(target *Target) bool
+}
+
+// TaggedPlan returns a plan which only kills the targets carrying at least
+// one of tags, e.g. a plan for SIGUSR1 which just stops ingestion:
+//
+//	dex.BindSignalPlan(syscall.SIGUSR1, dexter.TaggedPlan("ingest", "ingest"))
+func TaggedPlan(name string, tags ...string) KillPlan {
+	return KillPlan{
+		Name: name,
+		Skip: func(target *Target) bool {
+			for _, tag := range tags {
+				if target.HasTag(tag) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+}
+
+// GracefulPlan kills every target, it is used unless another plan is bound
+// to the signal or reason which started the shutdown
+var GracefulPlan = KillPlan{Name: "graceful"}
+
+// trigger is what started a shutdown, signal is nil for Shutdown calls.
+// early is set for signals held by WithMinUptime.
+type trigger struct {
+	signal os.Signal
+	reason string
+	early  bool
+	// restart is set for the shutdown of Restart, the reason is only
+	// what is shown
+	restart bool
+}
+
+type boundPlan struct {
+	plan   KillPlan
+	signal os.Signal
+	reason string
+}
+
+// BindSignalPlan makes shutdowns started by sig use plan.  When d owns the
+// OS signals and sig isn't one of the shutdown signals it starts listening
+// for it, so any signal can be routed to its own plan.
+func (d *Dexter) BindSignalPlan(sig os.Signal, plan KillPlan) {
+	d.mu.Lock()
+	d.plans = append(d.plans, boundPlan{plan: plan, signal: sig})
+	d.mu.Unlock()
+
+	signalOwner.Lock()
+	defer signalOwner.Unlock()
+	if signalOwner.dex == d {
+		signal.Notify(d.waiter, sig)
+	}
+}
+
+// BindReasonPlan makes shutdowns started by Shutdown(reason) use plan
+func (d *Dexter) BindReasonPlan(reason string, plan KillPlan) {
+	d.mu.Lock()
+	d.plans = append(d.plans, boundPlan{plan: plan, reason: reason})
+	d.mu.Unlock()
+}
+
+// Shutdown starts the shutdown WaitAndKill is waiting for, as if a signal had
+// arrived, reason is recorded in the report and selects the kill plan.  If
+// a shutdown is already pending the call is a no-op.
+func (d *Dexter) Shutdown(reason string) {
+	select {
+	case d.triggers <- trigger{reason: reason}:
+	default:
+	}
+}
+
+// planFor returns the plan bound to what triggered the shutdown, the most
+// recently bound plan wins
+func (d *Dexter) planFor(trig trigger) KillPlan {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := len(d.plans) - 1; i >= 0; i-- {
+		bound := d.plans[i]
+		if trig.signal != nil && bound.signal == trig.signal {
+			return bound.plan
+		}
+		if trig.signal == nil && bound.signal == nil && bound.reason == trig.reason {
+			return bound.plan
+		}
+	}
+	return GracefulPlan
+}
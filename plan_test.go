@@ -0,0 +1,72 @@
+package dexter
+
+import (
+	"os"
+	"testing"
+)
+
+func fastPlan() KillPlan {
+	return KillPlan{
+		Name: "fast",
+		Skip: func(t *Target) bool { return t.Phase() == PhaseFlush },
+	}
+}
+
+func TestSignalPlan(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.BindSignalPlan(os.Interrupt, fastPlan())
+	flush := NewTarget("flush")
+	dex.TrackPhase(PhaseFlush, flush)
+	dex.Track(NewTarget("workers"))
+
+	dex.SimulateSignal(os.Interrupt)
+	dex.WaitAndKill()
+
+	report := dex.LastReport()
+	if report.Plan != "fast" || flush.State() != TargetRunning || !report.Targets[1].Skipped {
+		t.Errorf("flush target was not skipped: %+v", report)
+	}
+}
+
+func TestReasonPlan(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.BindReasonPlan("evicted", fastPlan())
+	flush := NewTarget("flush")
+	dex.TrackPhase(PhaseFlush, flush)
+
+	dex.Shutdown("deploy")
+	dex.WaitAndKill()
+
+	report := dex.LastReport()
+	if report.Signal != nil || report.Reason != "deploy" || report.Plan != "graceful" {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if flush.State() != TargetStopped {
+		t.Error("graceful plan skipped the flush target")
+	}
+}
+
+type testSignal string
+
+func (s testSignal) Signal()        {}
+func (s testSignal) String() string { return string(s) }
+
+func TestTaggedPlan(t *testing.T) {
+	usr1 := testSignal("usr1")
+	dex := NewDexter(WithManualTrigger())
+	dex.BindSignalPlan(usr1, TaggedPlan("ingest", "ingest"))
+	ingest, workers := NewTarget("ingest"), NewTarget("workers")
+	ingest.Tag("ingest")
+	dex.Track(ingest)
+	dex.Track(workers)
+
+	dex.SimulateSignal(usr1)
+	dex.WaitAndKill()
+
+	if ingest.State() != TargetStopped || workers.State() != TargetRunning {
+		t.Errorf("ingest is %v, workers %v", ingest.State(), workers.State())
+	}
+	if plan := dex.LastReport().Plan; plan != "ingest" {
+		t.Errorf("ran the %s plan", plan)
+	}
+}
@@ -0,0 +1,42 @@
+//go:build !windows && !plan9 && !js && !wasip1
+// +build !windows,!plan9,!js,!wasip1
+
+package dexter
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestBoundSignalIsDelivered(t *testing.T) {
+	dex := NewDexter()
+	defer dex.ReleaseSignals()
+	dex.BindSignalPlan(syscall.SIGUSR1, KillPlan{Name: "usr1"})
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	dex.WaitAndKill()
+	if report := dex.LastReport(); report.Signal != syscall.SIGUSR1 || report.Plan != "usr1" {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestSIGHUPReloadsAfterRestart(t *testing.T) {
+	dex := NewDexter()
+	defer dex.ReleaseSignals()
+	built := make(chan struct{}, 1)
+	dex.SetReloader(&fakeReloader{build: func() (map[string]*Target, error) {
+		built <- struct{}{}
+		return nil, nil
+	}})
+	if err := dex.Restart(); err != nil {
+		t.Fatal(err)
+	}
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	select {
+	case <-built:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGHUP did not reload after a restart")
+	}
+}
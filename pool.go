@@ -0,0 +1,80 @@
+package dexter
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrPoolClosed is returned when submitting to a WorkerPool that was killed
+var ErrPoolClosed = errors.New("worker pool is closed")
+
+// WorkerPool is a Target which owns n worker goroutines fed by a job
+// channel.  When killed it stops accepting jobs, lets the workers drain
+// whatever is still queued and only then cancels the workers' context.
+type WorkerPool struct {
+	*Target
+	jobs   chan interface{}
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewWorkerPool starts n workers calling worker for each submitted job.
+// Track the pool with Dexter like any other target.
+func NewWorkerPool(name string, n int, worker func(ctx context.Context, job interface{})) *WorkerPool {
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := &WorkerPool{
+		Target: NewTarget(name),
+		jobs:   make(chan interface{}, n),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	pool.trackFunc(pool.stopIntake)
+
+	pool.Add(n)
+	for i := 0; i < n; i++ {
+		go pool.run(worker)
+	}
+	go func() {
+		pool.Wait()
+		pool.cancel()
+	}()
+	return pool
+}
+
+// Submit queues job for the workers, blocking while the queue is full.
+// It returns ErrPoolClosed once the pool has been killed.
+func (p *WorkerPool) Submit(job interface{}) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	p.jobs <- job
+	return nil
+}
+
+// Abort cancels the context handed to workers without waiting for the
+// queue to drain, for when a graceful drain takes too long
+func (p *WorkerPool) Abort() {
+	p.cancel()
+}
+
+func (p *WorkerPool) run(worker func(ctx context.Context, job interface{})) {
+	defer p.Done()
+	for job := range p.jobs {
+		worker(p.ctx, job)
+	}
+}
+
+func (p *WorkerPool) stopIntake() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+}
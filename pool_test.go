@@ -0,0 +1,31 @@
+package dexter
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWorkerPoolDrains(t *testing.T) {
+	var processed int32
+	pool := NewWorkerPool("pool", 2, func(ctx context.Context, job interface{}) {
+		if ctx.Err() != nil {
+			t.Error("worker context cancelled before the queue drained")
+		}
+		atomic.AddInt32(&processed, int32(job.(int)))
+	})
+
+	for i := 0; i < 10; i++ {
+		if err := pool.Submit(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.Kill()
+
+	if processed != 10 {
+		t.Errorf("processed %d of 10 jobs", processed)
+	}
+	if err := pool.Submit(1); err != ErrPoolClosed {
+		t.Errorf("submit after kill returned %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package dexter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PreemptionSource reports whether the machine is about to be taken away,
+// such as a spot instance interruption.  Deadline is when the machine goes
+// away, zero when the notice doesn't tell.
+type PreemptionSource interface {
+	Preempted(ctx context.Context) (deadline time.Time, preempted bool, err error)
+}
+
+// gcpNotice is how long GCP gives preempted instances to shut down
+const gcpNotice = 30 * time.Second
+
+// WatchPreemption polls source every interval and starts the graceful
+// shutdown with reason "preemption" when the machine is about to be taken
+// away.  The force kill window is resized to the notice period left, less
+// the last rites budget, so the drain uses all the time there is and no
+// more.  Watching stops when shutdown starts for any other reason.
+func (d *Dexter) WatchPreemption(source PreemptionSource, interval time.Duration) {
+	d.addTrigger("preemption-watch", TriggerFunc(func(stop context.Context) (string, bool) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			deadline, preempted, err := source.Preempted(ctx)
+			cancel()
+			switch {
+			case err != nil:
+				d.logf("Checking for preemption: %v\n", err)
+			case preempted:
+				if !deadline.IsZero() {
+					window := time.Until(deadline) - lastRitesBudget
+					if window < lastRitesBudget {
+						window = lastRitesBudget
+					}
+					d.SetForceKillInterval(window)
+				}
+				d.logf("Preemption notice received, shutting down within %v\n", d.forceKillInterval())
+				d.metrics.Count("shutdown.preemption", 1)
+				return "preemption", true
+			}
+			select {
+			case <-ticker.C:
+			case <-stop.Done():
+				return "", false
+			}
+		}
+	}))
+}
+
+// AWSPreemption watches the EC2 instance metadata service for spot
+// interruption notices and Auto Scaling lifecycle terminations.  Endpoint
+// defaults to http://169.254.169.254, Client to http.DefaultClient.
+type AWSPreemption struct {
+	Endpoint string
+	Client   *http.Client
+}
+
+// Preempted implements PreemptionSource, IMDSv2 is used when available
+func (a *AWSPreemption) Preempted(ctx context.Context) (time.Time, bool, error) {
+	endpoint := a.Endpoint
+	if endpoint == "" {
+		endpoint = "http://169.254.169.254"
+	}
+	token := a.token(ctx, endpoint)
+
+	body, found, err := a.get(ctx, endpoint+"/latest/meta-data/spot/instance-action", token)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if found {
+		var action struct {
+			Action string    `json:"action"`
+			Time   time.Time `json:"time"`
+		}
+		if err := json.Unmarshal(body, &action); err != nil {
+			return time.Time{}, false, fmt.Errorf("spot instance action: %w", err)
+		}
+		if action.Action == "terminate" || action.Action == "stop" {
+			return action.Time, true, nil
+		}
+	}
+
+	body, found, err = a.get(ctx, endpoint+"/latest/meta-data/autoscaling/target-lifecycle-state", token)
+	if err != nil || !found {
+		return time.Time{}, false, err
+	}
+	return time.Time{}, strings.TrimSpace(string(body)) == "Terminated", nil
+}
+
+// token fetches an IMDSv2 session token, "" falls back to IMDSv1
+func (a *AWSPreemption) token(ctx context.Context, endpoint string) string {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	resp, err := a.client().Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	token, _ := ioutil.ReadAll(resp.Body)
+	return string(token)
+}
+
+// get fetches url, found is false for a 404
+func (a *AWSPreemption) get(ctx context.Context, url, token string) (body []byte, found bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if token != "" {
+		req.Header.Set("X-aws-ec2-metadata-token", token)
+	}
+	return fetchMetadata(a.client(), req)
+}
+
+func (a *AWSPreemption) client() *http.Client {
+	if a.Client != nil {
+		return a.Client
+	}
+	return http.DefaultClient
+}
+
+// GCPPreemption watches the GCE metadata server for preemption of spot and
+// preemptible VMs, which get 30 seconds of notice.  Endpoint defaults to
+// http://metadata.google.internal, Client to http.DefaultClient.
+type GCPPreemption struct {
+	Endpoint string
+	Client   *http.Client
+}
+
+// Preempted implements PreemptionSource
+func (g *GCPPreemption) Preempted(ctx context.Context) (time.Time, bool, error) {
+	endpoint := g.Endpoint
+	if endpoint == "" {
+		endpoint = "http://metadata.google.internal"
+	}
+	url := endpoint + "/computeMetadata/v1/instance/preempted"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	body, _, err := fetchMetadata(client, req)
+	if err != nil || strings.TrimSpace(string(body)) != "TRUE" {
+		return time.Time{}, false, err
+	}
+	return time.Now().Add(gcpNotice), true, nil
+}
+
+// fetchMetadata sends req to a metadata server, found is false for a 404
+func fetchMetadata(client *http.Client, req *http.Request) (body []byte, found bool, err error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil, false, nil
+	default:
+		return nil, false, fmt.Errorf("%s: %s", req.URL, resp.Status)
+	}
+	body, err = ioutil.ReadAll(resp.Body)
+	return body, err == nil, err
+}
@@ -0,0 +1,61 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAWSPreemption(t *testing.T) {
+	interrupted := false
+	notice := time.Now().Add(2 * time.Minute).UTC().Truncate(time.Second)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/api/token":
+			fmt.Fprint(w, "token")
+		case "/latest/meta-data/spot/instance-action":
+			if r.Header.Get("X-aws-ec2-metadata-token") != "token" {
+				http.Error(w, "no token", http.StatusUnauthorized)
+			} else if interrupted {
+				fmt.Fprintf(w, `{"action": "terminate", "time": %q}`, notice.Format(time.RFC3339))
+			} else {
+				http.NotFound(w, r)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	aws := &AWSPreemption{Endpoint: srv.URL}
+	if _, preempted, err := aws.Preempted(context.Background()); preempted || err != nil {
+		t.Fatalf("preempted %v, %v", preempted, err)
+	}
+	interrupted = true
+	deadline, preempted, err := aws.Preempted(context.Background())
+	if !preempted || err != nil || !deadline.Equal(notice) {
+		t.Errorf("preempted %v at %v, %v", preempted, deadline, err)
+	}
+}
+
+type fakePreemption time.Time
+
+func (f fakePreemption) Preempted(context.Context) (time.Time, bool, error) {
+	return time.Time(f), true, nil
+}
+
+func TestWatchPreemption(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.WatchPreemption(fakePreemption(time.Now().Add(10*time.Second)), time.Millisecond)
+
+	dex.WaitAndKill()
+	if reason := dex.LastReport().Reason; reason != "preemption" {
+		t.Errorf("shut down for %q", reason)
+	}
+	if window := dex.forceKillWindow; window > 10*time.Second-lastRitesBudget || window < 8*time.Second {
+		t.Errorf("force kill window is %v", window)
+	}
+}
@@ -0,0 +1,68 @@
+package dexter
+
+import (
+	"os"
+	"time"
+)
+
+// WithSignals replaces the signals a root Dexter listens for, SIGINT and
+// SIGTERM by default
+func WithSignals(sigs ...os.Signal) Option {
+	return func(d *Dexter) {
+		d.signals = sigs
+	}
+}
+
+// WithShutdownDelay makes shutdown wait for delay after Stopping is closed
+// and readiness turned false, before any target is killed, so load
+// balancers stop routing new requests first.  The force kill window starts
+// after the delay.
+func WithShutdownDelay(delay time.Duration) Option {
+	return func(d *Dexter) {
+		d.shutdownDelay = delay
+	}
+}
+
+// WithForceKillWindow is SetForceKillInterval as an option
+func WithForceKillWindow(window time.Duration) Option {
+	return func(d *Dexter) {
+		d.forceKillWindow = window
+	}
+}
+
+// Platform presets tune the signal set, the shutdown delay and the force
+// kill window to each platform's documented termination behaviour.  The
+// force kill window ends ahead of the platform's SIGKILL so the last rites
+// still run.  Pass them to NewDexter before any option overriding them.
+var (
+	// ProfileHeroku: dynos get SIGTERM and are killed 30 seconds later,
+	// the router stops routing to them on its own
+	ProfileHeroku = platformProfile(30*time.Second, 0)
+	// ProfileCloudRun: instances get SIGTERM and are killed 10 seconds
+	// later, requests stop being routed to them on its own
+	ProfileCloudRun = platformProfile(10*time.Second, 0)
+	// ProfileLambdaExtensions: with external extensions registered the
+	// runtime gets SIGTERM and 2 seconds to shut down
+	ProfileLambdaExtensions = platformProfile(2*time.Second, 0)
+	// ProfileECS: tasks get SIGTERM and are killed after the default stop
+	// timeout of 30 seconds, load balancer deregistration runs at the same
+	// time so new requests are still accepted for a few seconds
+	ProfileECS = platformProfile(30*time.Second, 5*time.Second)
+)
+
+// platformProfile returns the options for a platform which kills the
+// process grace after sending SIGTERM
+func platformProfile(grace, delay time.Duration) Option {
+	margin := grace / 10
+	if margin < lastRitesBudget {
+		margin = lastRitesBudget
+	}
+	if margin > 2*time.Second {
+		margin = 2 * time.Second
+	}
+	return func(d *Dexter) {
+		WithSignals(terminateSignals...)(d)
+		WithShutdownDelay(delay)(d)
+		WithForceKillWindow(grace - delay - margin)(d)
+	}
+}
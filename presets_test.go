@@ -0,0 +1,44 @@
+package dexter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlatformProfiles(t *testing.T) {
+	for name, tc := range map[string]struct {
+		profile Option
+		grace   time.Duration
+	}{
+		"heroku":   {ProfileHeroku, 30 * time.Second},
+		"cloudrun": {ProfileCloudRun, 10 * time.Second},
+		"lambda":   {ProfileLambdaExtensions, 2 * time.Second},
+		"ecs":      {ProfileECS, 30 * time.Second},
+	} {
+		dex := NewDexter(WithManualTrigger(), tc.profile)
+		if total := dex.shutdownDelay + dex.forceKillWindow; total <= 0 || total+lastRitesBudget > tc.grace {
+			t.Errorf("%s: delay %v and window %v don't fit %v", name, dex.shutdownDelay, dex.forceKillWindow, tc.grace)
+		}
+	}
+}
+
+func TestShutdownDelay(t *testing.T) {
+	dex := NewDexter(WithManualTrigger(), WithShutdownDelay(30*time.Millisecond))
+	target := NewTarget("server")
+	dex.Track(target)
+	go dex.Shutdown("test")
+	go dex.WaitAndKill()
+
+	<-dex.Stopping()
+	if dex.Serving() {
+		t.Error("still serving during the delay")
+	}
+	if target.State() != TargetRunning {
+		t.Error("target killed during the delay")
+	}
+	for state := range target.Watch() {
+		if state == TargetStopped {
+			return
+		}
+	}
+}
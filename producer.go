@@ -0,0 +1,101 @@
+package dexter
+
+import (
+	"sync"
+	"time"
+)
+
+// producerProbe bounds how long closing a channel waits for its producers
+const producerProbe = 100 * time.Millisecond
+
+// producers counts the goroutines sending on a tracked channel
+type producers struct {
+	mu      sync.Mutex
+	active  int
+	idle    chan struct{}
+	pending func()
+}
+
+// TrackProducer registers a goroutine sending on channel, which should be
+// tracked with TrackChannel, and returns the func to call once it stopped
+// sending.  When the target is killed while producers are still active
+// it waits a little for them, then logs a warning naming the target and
+// channel and closes the channel only once the last producer is done,
+// instead of making a producer panic with "send on closed channel".
+func (t *Target) TrackProducer(channel interface{}) (done func()) {
+	t.mu.Lock()
+	if t.producers == nil {
+		t.producers = map[interface{}]*producers{}
+	}
+	p := t.producers[channel]
+	if p == nil {
+		p = &producers{}
+		t.producers[channel] = p
+	}
+	t.mu.Unlock()
+
+	p.mu.Lock()
+	if p.active == 0 {
+		p.idle = make(chan struct{})
+	}
+	p.active++
+	p.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mu.Lock()
+			p.active--
+			var pending func()
+			if p.active == 0 {
+				close(p.idle)
+				pending, p.pending = p.pending, nil
+			}
+			p.mu.Unlock()
+			if pending != nil {
+				pending()
+			}
+		})
+	}
+}
+
+// closeChannel closes channel unless producers are still sending on it
+// after a short wait, then it is closed by the last one to finish.  Closing
+// a channel which was already closed is reported instead of panicking.
+func (t *Target) closeChannel(channel interface{}) error {
+	closeIt := func() error {
+		err := safeCloseValue(channel)
+		if err != nil {
+			t.logf("Error closing %s in target %s: %v\n", t.channelName(channel), t.name, err)
+		}
+		t.released(ResourceChannel, t.channelName(channel), channel, err)
+		return err
+	}
+	t.mu.Lock()
+	p := t.producers[channel]
+	t.mu.Unlock()
+	if p == nil {
+		return closeIt()
+	}
+
+	p.mu.Lock()
+	active, idle := p.active, p.idle
+	p.mu.Unlock()
+	if active > 0 {
+		select {
+		case <-idle:
+		case <-time.After(producerProbe):
+		}
+	}
+
+	p.mu.Lock()
+	if p.active > 0 {
+		t.logf("Warning: target %s has %d producers still sending on %s, closing it once they are done\n",
+			t.name, p.active, t.channelName(channel))
+		p.pending = func() { closeIt() }
+		p.mu.Unlock()
+		return nil
+	}
+	p.mu.Unlock()
+	return closeIt()
+}
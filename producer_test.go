@@ -0,0 +1,46 @@
+package dexter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProducerDelaysClose(t *testing.T) {
+	target := NewTarget("pipeline")
+	ch := make(chan int, 10)
+	target.TrackChannel(ch)
+	done := target.TrackProducer(ch)
+
+	target.kill()
+	// the producer outlived the probe, sending must not panic
+	ch <- 1
+	select {
+	case _, ok := <-ch:
+		if !ok {
+			t.Fatal("channel closed while its producer was active")
+		}
+	default:
+		t.Fatal("value lost")
+	}
+
+	done()
+	if _, ok := <-ch; ok {
+		t.Error("channel not closed after its last producer was done")
+	}
+}
+
+func TestProducerFinishesDuringProbe(t *testing.T) {
+	target := NewTarget("pipeline")
+	ch := make(chan int)
+	target.TrackChannel(ch)
+	done := target.TrackProducer(ch)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		done()
+	}()
+
+	target.kill()
+	if _, ok := <-ch; ok {
+		t.Error("channel not closed once the producer finished")
+	}
+}
@@ -0,0 +1,65 @@
+package dexter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"time"
+)
+
+// profileCaptureTimeout bounds how long a forced exit may be delayed
+// by writing profiles
+const profileCaptureTimeout = 2 * time.Second
+
+// SetProfileDir makes dexter write heap, goroutine and block profiles to dir
+// right before it force exits, so a hung shutdown can be analyzed after the
+// fact.  Block profiles are only populated if runtime.SetBlockProfileRate
+// was called by the application.  An empty dir disables the capture.
+func (d *Dexter) SetProfileDir(dir string) {
+	d.profileDir = dir
+}
+
+// captureProfiles writes profiles to dir, giving up after timeout
+func captureProfiles(dir string, timeout time.Duration) {
+	done := make(chan struct{})
+	go labelled("", "profile", func() {
+		defer close(done)
+		prefix := fmt.Sprintf("dexter-%d-%d-", os.Getpid(), time.Now().Unix())
+		for _, p := range []struct {
+			name  string
+			file  string
+			debug int
+		}{
+			// goroutine dumps are far more useful with full stacks
+			{"goroutine", "goroutine.txt", 2},
+			{"heap", "heap.pprof", 0},
+			{"block", "block.pprof", 0},
+		} {
+			path := filepath.Join(dir, prefix+p.file)
+			if err := writeProfile(path, p.name, p.debug); err != nil {
+				dlog.Printf("Failed to write %s profile: %v\n", p.name, err)
+				continue
+			}
+			dlog.Printf("Wrote %s profile to %s\n", p.name, path)
+		}
+	})
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		dlog.Println("Timed out writing profiles")
+	}
+}
+
+func writeProfile(path, name string, debug int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := pprof.Lookup(name).WriteTo(f, debug); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
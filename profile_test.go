@@ -0,0 +1,26 @@
+package dexter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCaptureProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dexter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	captureProfiles(dir, time.Second)
+
+	for _, suffix := range []string{"goroutine.txt", "heap.pprof", "block.pprof"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, "dexter-*-"+suffix))
+		if len(matches) != 1 {
+			t.Errorf("expected one %s profile, found %v", suffix, matches)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package dexter
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// progressInterval is how often the progress display is redrawn
+const progressInterval = 200 * time.Millisecond
+
+// WithTerminalProgress renders an in-place progress display on stdout
+// during shutdown, with the targets left, the target being killed and the
+// time until the force kill, instead of log lines.  It does nothing
+// unless stdout is a terminal.
+func WithTerminalProgress() Option {
+	return func(d *Dexter) {
+		if isTerminal(os.Stdout) {
+			d.progress = os.Stdout
+		}
+	}
+}
+
+// isTerminal reports whether f is a character device, which is as close
+// as the standard library gets to isatty
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// showProgress draws the progress of the shutdown of targets to w until
+// the returned func is called, counting down to force's expiry.  The log
+// lines of d and targets are discarded meanwhile, those of other Dexters
+// are not.
+func (d *Dexter) showProgress(w io.Writer, targets []*Target, force *forceTimer) func() {
+	atomic.AddInt32(&d.muted, 1)
+	for _, target := range targets {
+		atomic.AddInt32(&target.muted, 1)
+	}
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			d.drawProgress(w, targets, force)
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-stopped
+		d.drawProgress(w, targets, force)
+		fmt.Fprintln(w)
+		for _, target := range targets {
+			atomic.AddInt32(&target.muted, -1)
+		}
+		atomic.AddInt32(&d.muted, -1)
+	}
+}
+
+// logf logs to the package logger unless d is showing progress
+func (d *Dexter) logf(format string, args ...interface{}) {
+	if atomic.LoadInt32(&d.muted) == 0 {
+		dlog.Printf(format, args...)
+	}
+}
+
+// alertf is logf for lines which must not be discarded, such as the force
+// exit.  While d is showing progress they are written below the progress
+// line, in the package logger's format.
+func (d *Dexter) alertf(format string, args ...interface{}) {
+	if atomic.LoadInt32(&d.muted) == 0 || d.progress == nil {
+		dlog.Printf(format, args...)
+		return
+	}
+	fmt.Fprintln(d.progress)
+	log.New(d.progress, dlog.Prefix(), dlog.Flags()).Printf(format, args...)
+}
+
+// drawProgress overwrites the current terminal line with the progress
+func (d *Dexter) drawProgress(w io.Writer, targets []*Target, force *forceTimer) {
+	report := d.reportSoFar()
+	if report == nil {
+		report = d.LastReport()
+	}
+	if report == nil {
+		return
+	}
+	done := len(report.Targets)
+	line := fmt.Sprintf("Shutting down (%s): %d/%d targets done", report.Reason, done, len(targets))
+	if done < len(targets) {
+		line += fmt.Sprintf(", killing %s", targets[done].name)
+		if left, ok := force.left(); ok {
+			if left < 0 {
+				left = 0
+			}
+			line += fmt.Sprintf(", force kill in %ds", int(left.Round(time.Second)/time.Second))
+		}
+	}
+	// carriage return and clear to the end of the line
+	fmt.Fprintf(w, "\r\x1b[K%s", line)
+}
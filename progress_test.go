@@ -0,0 +1,119 @@
+package dexter
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	var buf bytes.Buffer
+	dex := NewDexter(WithManualTrigger())
+	dex.progress = &buf
+	dex.Track(NewTarget("db"))
+	dex.Track(NewTarget("cache"))
+
+	dex.Shutdown("migration done")
+	dex.WaitAndKill()
+
+	out := buf.String()
+	if !strings.Contains(out, "\r\x1b[KShutting down (migration done): 2/2 targets done\n") {
+		t.Errorf("unexpected progress %q", out)
+	}
+	if strings.Contains(out, "[Dexter]") {
+		t.Error("log lines mixed into the progress display")
+	}
+}
+
+func TestProgressMutesOnlyItsDexter(t *testing.T) {
+	var logs bytes.Buffer
+	out := dlog.Writer()
+	dlog.SetOutput(&logs)
+	defer dlog.SetOutput(out)
+
+	quiet := NewDexter(WithManualTrigger())
+	quiet.progress = &bytes.Buffer{}
+	hold := make(chan struct{})
+	slow := NewTarget("slow")
+	slow.TrackCloser(closerFunc(func() error {
+		<-hold
+		return nil
+	}))
+	quiet.Track(slow)
+	closed := make(chan struct{})
+	go func() {
+		quiet.Close()
+		close(closed)
+	}()
+	for atomic.LoadInt32(&quiet.muted) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	loud := NewDexter(WithManualTrigger())
+	loud.Track(NewTarget("db"))
+	loud.Close()
+	close(hold)
+	<-closed
+
+	if !strings.Contains(logs.String(), "Killing target db") {
+		t.Error("another dexter's log lines were discarded")
+	}
+	if strings.Contains(logs.String(), "Killing target slow") {
+		t.Error("log lines mixed into the progress display")
+	}
+	if dlog.Writer() != &logs {
+		t.Error("the package logger's output was changed")
+	}
+}
+
+// lockedBuffer is a bytes.Buffer safe to write from several goroutines
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestProgressShowsForceExit(t *testing.T) {
+	var buf lockedBuffer
+	dex := NewDexter(WithManualTrigger())
+	dex.progress = &buf
+	dex.SetForceKillInterval(20 * time.Millisecond)
+	dex.exitFunc = func(int) {}
+	dex.Track(slowTarget("stuck", 100*time.Millisecond))
+
+	dex.Close()
+	if !strings.Contains(buf.String(), "Timeout! - force exiting") {
+		t.Errorf("force exit not shown with the progress %q", buf.String())
+	}
+}
+
+func TestProgressCountsDownToContextDeadline(t *testing.T) {
+	var buf lockedBuffer
+	dex := NewDexter(WithManualTrigger())
+	dex.progress = &buf
+	dex.SetForceKillInterval(time.Hour)
+	dex.Track(slowTarget("slow", 100*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	dex.Shutdown("deploy")
+	dex.WaitAndKillContext(ctx)
+	if !strings.Contains(buf.String(), "killing slow, force kill in 3s") {
+		t.Errorf("countdown ignores the context's deadline %q", buf.String())
+	}
+}
@@ -0,0 +1,122 @@
+package dexter
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrQueueClosed is returned when submitting to a JobQueue that was killed
+var ErrQueueClosed = errors.New("job queue is closed")
+
+// JobQueue is a Target running n workers over an in-memory queue.  Unlike a
+// WorkerPool it doesn't drain the queue when killed: it stops intake, lets
+// each worker finish the job it is on and hands every job still queued to
+// persist, so no work is silently lost.
+type JobQueue struct {
+	*Target
+	jobs    chan interface{}
+	stop    chan struct{}
+	workers sync.WaitGroup
+	persist func(jobs []interface{}) error
+
+	mu       sync.RWMutex
+	closed   bool
+	leftover []interface{}
+}
+
+// NewJobQueue starts n workers calling worker for jobs from a queue holding
+// up to size jobs.  Track the queue with Dexter like any other target.
+func NewJobQueue(name string, n, size int, worker func(job interface{}),
+	persist func(jobs []interface{}) error) *JobQueue {
+	q := &JobQueue{
+		Target:  NewTarget(name),
+		jobs:    make(chan interface{}, size),
+		stop:    make(chan struct{}),
+		persist: persist,
+	}
+	q.trackFunc(q.stopIntake)
+	q.TrackCloser(queuePersister{q})
+
+	q.Add(n)
+	q.workers.Add(n)
+	for i := 0; i < n; i++ {
+		go q.run(worker)
+	}
+	return q
+}
+
+// Submit queues job, blocking while the queue is full.  It returns
+// ErrQueueClosed once the queue has been killed.
+func (q *JobQueue) Submit(job interface{}) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	q.jobs <- job
+	return nil
+}
+
+func (q *JobQueue) run(worker func(job interface{})) {
+	defer q.Done()
+	defer q.workers.Done()
+	for {
+		select {
+		case <-q.stop:
+			return
+		case job, ok := <-q.jobs:
+			if !ok {
+				return
+			}
+			// both cases may have been ready, stopping wins
+			select {
+			case <-q.stop:
+				q.mu.Lock()
+				q.leftover = append(q.leftover, job)
+				q.mu.Unlock()
+				return
+			default:
+			}
+			worker(job)
+		}
+	}
+}
+
+func (q *JobQueue) stopIntake() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.stop)
+		close(q.jobs)
+	}
+}
+
+// queuePersister hands the jobs left in the queue to persist once every
+// worker has finished its current job
+type queuePersister struct {
+	q *JobQueue
+}
+
+func (p queuePersister) Close() error {
+	q := p.q
+	q.workers.Wait()
+
+	q.mu.Lock()
+	pending := q.leftover
+	q.leftover = nil
+	q.mu.Unlock()
+	for job := range q.jobs {
+		pending = append(pending, job)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	dlog.Printf("Persisting %d pending jobs of %s\n", len(pending), q.name)
+	if err := q.persist(pending); err != nil {
+		return fmt.Errorf("persisting %d pending jobs: %w", len(pending), err)
+	}
+	return nil
+}
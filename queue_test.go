@@ -0,0 +1,54 @@
+package dexter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJobQueuePersistsPending(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var processed, persisted []interface{}
+
+	q := NewJobQueue("queue", 1, 10, func(job interface{}) {
+		if job == 0 {
+			close(started)
+			<-release
+		}
+		processed = append(processed, job)
+	}, func(jobs []interface{}) error {
+		persisted = jobs
+		return nil
+	})
+
+	for i := 0; i < 5; i++ {
+		q.Submit(i)
+	}
+	<-started
+	q.OnRelease(func(r Release) {
+		// the in-flight job finishes after intake stopped
+		if r.Kind == ResourceFunc {
+			close(release)
+		}
+	})
+	q.Kill()
+
+	if len(processed) != 1 || len(persisted) != 4 {
+		t.Errorf("processed %v, persisted %v", processed, persisted)
+	}
+	if err := q.Submit(5); err != ErrQueueClosed {
+		t.Errorf("submit after kill returned %v", err)
+	}
+}
+
+func TestJobQueuePersistError(t *testing.T) {
+	q := NewJobQueue("queue", 0, 1, nil, func([]interface{}) error {
+		return errors.New("disk full")
+	})
+	q.Submit(1)
+
+	errs, _ := killTarget(q.Target, limit{}, nil)
+	if len(errs) != 1 || errs[0].Error() != "persisting 1 pending jobs: disk full" {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
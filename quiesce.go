@@ -0,0 +1,124 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Quiescer is implemented by resources which can stop taking new work and
+// wait for work in progress, without being closed, so they can resume later
+type Quiescer interface {
+	// Quiesce stops intake and waits for work in progress to finish, or
+	// for ctx to be done
+	Quiesce(ctx context.Context) error
+	// Resume takes work again
+	Resume() error
+}
+
+// TrackQuiescer registers q to be quiesced and resumed with the target by
+// Dexter.Quiesce and Dexter.Resume
+func (t *Target) TrackQuiescer(q Quiescer) {
+	t.mu.Lock()
+	t.quiescers = append(t.quiescers, q)
+	t.mu.Unlock()
+}
+
+// Quiesce runs the "stop taking new work" half of a shutdown without closing
+// anything: readiness turns false and every target's quiescers are quiesced,
+// in kill order, so in-flight work drains.  Use it to drain a node for
+// maintenance, Resume brings it back without a restart.
+func (d *Dexter) Quiesce(ctx context.Context) (err error) {
+	defer func(started time.Time) { d.record("quiesce", started, err, nil) }(time.Now())
+	d.mu.Lock()
+	d.quiesced = true
+	d.mu.Unlock()
+	d.logf("Quiescing\n")
+
+	var failed []string
+	for _, target := range d.killOrder() {
+		failed = append(failed, target.quiesce(ctx)...)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("quiesce: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Resume undoes Quiesce, quiescers are resumed in reverse kill order and
+// readiness turns true again
+func (d *Dexter) Resume() (err error) {
+	defer func(started time.Time) { d.record("resume", started, err, nil) }(time.Now())
+	var failed []string
+	targets := d.killOrder()
+	for i := len(targets) - 1; i >= 0; i-- {
+		failed = append(failed, targets[i].resume()...)
+	}
+
+	d.mu.Lock()
+	d.quiesced = false
+	d.mu.Unlock()
+	d.logf("Resumed\n")
+	if len(failed) > 0 {
+		return fmt.Errorf("resume: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// quiesce quiesces the target's quiescers in order, returning a message
+// for each one which failed
+func (t *Target) quiesce(ctx context.Context) (failed []string) {
+	t.mu.Lock()
+	quiescers := t.quiescers
+	t.mu.Unlock()
+	for _, q := range quiescers {
+		if err := q.Quiesce(ctx); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", t.name, err))
+		}
+	}
+	return failed
+}
+
+// resume resumes the target's quiescers in reverse order, returning a
+// message for each one which failed
+func (t *Target) resume() (failed []string) {
+	t.mu.Lock()
+	quiescers := t.quiescers
+	t.mu.Unlock()
+	for i := len(quiescers) - 1; i >= 0; i-- {
+		if err := quiescers[i].Resume(); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", t.name, err))
+		}
+	}
+	return failed
+}
+
+// Serving reports whether the process should receive traffic, it is false
+// before Ready with WithReadyGate, while quiesced and once shutdown started
+func (d *Dexter) Serving() bool {
+	if !d.isReady() {
+		return false
+	}
+	select {
+	case <-d.Stopping():
+		return false
+	default:
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return !d.quiesced
+}
+
+// ReadinessHandler answers readiness probes, 200 OK while Serving and
+// 503 Service Unavailable otherwise
+func (d *Dexter) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !d.Serving() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+}
@@ -0,0 +1,70 @@
+package dexter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestQuiesceAndResume(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	target := NewTarget("http")
+	dex.Track(target)
+	inflight := NewInFlight(target)
+
+	probe := func() int {
+		rec := httptest.NewRecorder()
+		dex.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/ready", nil))
+		return rec.Code
+	}
+
+	done, _ := inflight.Begin()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		done()
+	}()
+	if err := dex.Quiesce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if inflight.Count() != 0 {
+		t.Error("quiesce returned before in-flight work drained")
+	}
+	if _, ok := inflight.Begin(); ok {
+		t.Error("new work accepted while quiesced")
+	}
+	if probe() != http.StatusServiceUnavailable {
+		t.Error("ready while quiesced")
+	}
+
+	if err := dex.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if probe() != http.StatusOK {
+		t.Error("not ready after resume")
+	}
+	if done, ok := inflight.Begin(); !ok {
+		t.Error("work rejected after resume")
+	} else {
+		done()
+	}
+	if target.State() != TargetRunning {
+		t.Errorf("quiescing changed the target to %v", target.State())
+	}
+}
+
+func TestQuiesceDeadline(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	target := NewTarget("http")
+	dex.Track(target)
+	inflight := NewInFlight(target)
+	done, _ := inflight.Begin()
+	defer done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := dex.Quiesce(ctx); err == nil {
+		t.Error("expected quiesce to time out")
+	}
+}
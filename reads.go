@@ -0,0 +1,181 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ReadsCutOffError is returned by ReadTracker.Close when streaming reads
+// had to be cut off, Reads describes each with the bytes it had read
+type ReadsCutOffError struct {
+	Reads []string
+}
+
+func (e *ReadsCutOffError) Error() string {
+	return fmt.Sprintf("cut off %d streaming reads: %s", len(e.Reads), strings.Join(e.Reads, ", "))
+}
+
+// ReadTracker keeps track of long running streaming reads, such as object
+// store downloads or tail -f style readers, which would otherwise hold a
+// target open until the force kill.  On Close reads get up to grace to
+// finish, then the rest have their context canceled and their body closed.
+// Hand it to TrackCloser, the bytes read by every read cut off are in the
+// shutdown report.
+type ReadTracker struct {
+	grace time.Duration
+
+	mu      sync.Mutex
+	reads   map[*trackedRead]struct{}
+	closing bool
+	drained chan struct{}
+	cut     []string
+}
+
+// NewReadTracker returns a tracker giving reads up to grace to finish
+func NewReadTracker(grace time.Duration) *ReadTracker {
+	return &ReadTracker{
+		grace:   grace,
+		reads:   map[*trackedRead]struct{}{},
+		drained: make(chan struct{}),
+	}
+}
+
+// trackedRead is a body tracked by a ReadTracker
+type trackedRead struct {
+	tracker *ReadTracker
+	name    string
+	body    io.ReadCloser
+	cancel  context.CancelFunc
+	n       int64
+	once    sync.Once
+	err     error
+}
+
+// Track wraps body, whose read is called name in diagnostics, cancel is
+// the read's context cancel func and may be nil.  Read from and close the
+// returned reader instead of body.  A read tracked after the tracker was
+// closed is cut off right away.
+func (r *ReadTracker) Track(name string, body io.ReadCloser, cancel context.CancelFunc) io.ReadCloser {
+	read := &trackedRead{tracker: r, name: name, body: body, cancel: cancel}
+	r.mu.Lock()
+	closing := r.closing
+	if !closing {
+		r.reads[read] = struct{}{}
+	}
+	r.mu.Unlock()
+	if closing {
+		read.cutOff()
+	}
+	return read
+}
+
+// Read reads from the body, counting the bytes read
+func (t *trackedRead) Read(p []byte) (int, error) {
+	n, err := t.body.Read(p)
+	atomic.AddInt64(&t.n, int64(n))
+	return n, err
+}
+
+// Close closes the body and stops tracking the read
+func (t *trackedRead) Close() error {
+	t.once.Do(func() {
+		t.err = t.body.Close()
+	})
+	t.tracker.forget(t)
+	return t.err
+}
+
+// cutOff cancels the read and closes its body
+func (t *trackedRead) cutOff() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.once.Do(func() {
+		t.err = t.body.Close()
+	})
+}
+
+// String describes the read with the bytes it read so far
+func (t *trackedRead) String() string {
+	return fmt.Sprintf("%s (%d bytes read)", t.name, atomic.LoadInt64(&t.n))
+}
+
+// forget stops tracking read
+func (r *ReadTracker) forget(read *trackedRead) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.reads, read)
+	if r.closing && len(r.reads) == 0 {
+		select {
+		case <-r.drained:
+		default:
+			close(r.drained)
+		}
+	}
+}
+
+// Open returns how many reads are in progress
+func (r *ReadTracker) Open() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.reads)
+}
+
+// Close waits up to the grace period for the reads in progress and cuts
+// off the rest, returning a *ReadsCutOffError if there were any
+func (r *ReadTracker) Close() error {
+	r.mu.Lock()
+	r.closing = true
+	if len(r.reads) == 0 {
+		r.mu.Unlock()
+		return nil
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.drained:
+		return nil
+	case <-time.After(r.grace):
+	}
+
+	r.mu.Lock()
+	var reads []*trackedRead
+	for read := range r.reads {
+		reads = append(reads, read)
+		delete(r.reads, read)
+	}
+	r.mu.Unlock()
+	if len(reads) == 0 {
+		return nil
+	}
+
+	var cut []string
+	for _, read := range reads {
+		read.cutOff()
+		cut = append(cut, read.String())
+	}
+	sort.Strings(cut)
+	r.mu.Lock()
+	r.cut = cut
+	r.mu.Unlock()
+	dlog.Printf("Cut off %d streaming reads\n", len(cut))
+	return &ReadsCutOffError{Reads: cut}
+}
+
+// ReportDetails adds the reads which were cut off to the shutdown report
+func (r *ReadTracker) ReportDetails() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	details := map[string]string{"reads.cut": strconv.Itoa(len(r.cut))}
+	for i, read := range r.cut {
+		details["reads.cut."+strconv.Itoa(i)] = read
+	}
+	return details
+}
@@ -0,0 +1,57 @@
+package dexter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadTracker(t *testing.T) {
+	reads := NewReadTracker(10 * time.Millisecond)
+	target := NewTarget("downloads")
+	target.TrackCloser(reads)
+
+	done := reads.Track("small", ioutil.NopCloser(strings.NewReader("done")), nil)
+	if _, err := ioutil.ReadAll(done); err != nil {
+		t.Fatal(err)
+	}
+	done.Close()
+
+	pr, pw := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	body := reads.Track("s3://bucket/big", pr, cancel)
+	go pw.Write([]byte("12345"))
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(body, buf); err != nil {
+		t.Fatal(err)
+	}
+	failed := make(chan error, 1)
+	go func() {
+		_, err := body.Read(buf)
+		failed <- err
+	}()
+	if reads.Open() != 1 {
+		t.Fatalf("%d reads open", reads.Open())
+	}
+
+	var cut *ReadsCutOffError
+	if err := target.Close(); !errors.As(err, &cut) || len(cut.Reads) != 1 {
+		t.Fatalf("got %v", err)
+	}
+	if cut.Reads[0] != "s3://bucket/big (5 bytes read)" {
+		t.Errorf("cut off %q", cut.Reads[0])
+	}
+	if ctx.Err() == nil {
+		t.Error("read context not canceled")
+	}
+	if err := <-failed; err == nil {
+		t.Error("read kept going after being cut off")
+	}
+	if details := reads.ReportDetails(); details["reads.cut"] != "1" {
+		t.Errorf("details %v", details)
+	}
+}
@@ -0,0 +1,74 @@
+package dexter
+
+import (
+	"context"
+	"time"
+)
+
+// WithReadyGate makes shutdowns wait for Ready, so a signal received while
+// main is still building and tracking targets doesn't kill a half built
+// target list.  The signal is remembered and shutdown starts as soon as
+// Ready is called, or when the signal is sent again, or once the force
+// kill window passed, so a hung startup can still be stopped.  Until then
+// Serving is false.
+func WithReadyGate() Option {
+	return func(d *Dexter) {
+		d.ready = make(chan struct{})
+	}
+}
+
+// Ready marks startup complete, every target that should be killed has been
+// tracked.  Start calls it once every start function succeeded.  It
+// matters with WithReadyGate and WithReadinessFile, calling it again does
+// nothing.
+func (d *Dexter) Ready() {
+	d.writeReadinessFile()
+	if d.ready == nil {
+		return
+	}
+	d.readyOnce.Do(func() {
+		d.logf("Startup complete\n")
+		close(d.ready)
+	})
+}
+
+// isReady reports whether startup is complete, it always is without
+// WithReadyGate
+func (d *Dexter) isReady() bool {
+	if d.ready == nil {
+		return true
+	}
+	select {
+	case <-d.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// awaitReady holds a shutdown triggered during startup until Ready, a
+// repeated signal or the end of the force kill window, ok is false when d
+// was closed or ctx done meanwhile
+func (d *Dexter) awaitReady(ctx context.Context, trig trigger) (ok bool) {
+	if d.isReady() {
+		return true
+	}
+	window := d.forceKillInterval()
+	d.logf("Shutdown requested during startup (%s), waiting up to %v for Ready\n", trig.reason, window)
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+	select {
+	case <-d.ready:
+		return true
+	case <-timer.C:
+		d.logf("Startup did not complete within %v, shutting down\n", window)
+		return true
+	case sig := <-d.waiter:
+		d.logf("Received %v signal again, shutting down before Ready\n", sig)
+		return true
+	case <-d.closed:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,64 @@
+package dexter
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestReadyGate(t *testing.T) {
+	dex := NewDexter(WithManualTrigger(), WithReadyGate())
+	done := make(chan struct{})
+	go func() {
+		dex.WaitAndKill()
+		close(done)
+	}()
+	dex.SimulateSignal(os.Interrupt)
+
+	late := NewTarget("late")
+	time.Sleep(20 * time.Millisecond)
+	if dex.Serving() {
+		t.Error("serving before Ready")
+	}
+	dex.Track(late)
+	dex.Ready()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("shutdown did not start after Ready")
+	}
+	if late.State() != TargetStopped {
+		t.Error("target tracked during startup was missed")
+	}
+}
+
+func TestReadyGateBounded(t *testing.T) {
+	for _, repeat := range []bool{false, true} {
+		dex := NewDexter(WithManualTrigger(), WithReadyGate())
+		dex.SetForceKillInterval(time.Minute)
+		if !repeat {
+			dex.SetForceKillInterval(20 * time.Millisecond)
+		}
+		done := make(chan struct{})
+		go func() {
+			dex.WaitAndKill()
+			close(done)
+		}()
+		dex.SimulateSignal(os.Interrupt)
+
+		timeout := time.After(time.Second)
+		for waiting := true; waiting; {
+			select {
+			case <-done:
+				waiting = false
+			case <-time.After(10 * time.Millisecond):
+				if repeat {
+					dex.SimulateSignal(os.Interrupt)
+				}
+			case <-timeout:
+				t.Fatalf("repeat %v: shutdown waits for a hung startup", repeat)
+			}
+		}
+	}
+}
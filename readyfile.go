@@ -0,0 +1,40 @@
+package dexter
+
+import (
+	"os"
+	"strconv"
+)
+
+// WithReadinessFile makes dexter maintain a readiness sentinel file at
+// path for probes which check files rather than HTTP endpoints, such as
+// systemd path units or HAProxy agent checks.  The file, holding the PID,
+// is written by Ready and removed as soon as shutdown starts, before any
+// target is killed.  A stale file left behind by a crash is removed by
+// NewDexter.
+func WithReadinessFile(path string) Option {
+	return func(d *Dexter) {
+		d.readinessPath = path
+		d.removeReadinessFile()
+	}
+}
+
+// writeReadinessFile writes the readiness file, if one is set
+func (d *Dexter) writeReadinessFile() {
+	if d.readinessPath == "" {
+		return
+	}
+	pid := strconv.Itoa(os.Getpid()) + "\n"
+	if err := writeFileAtomic(d.readinessPath, []byte(pid)); err != nil {
+		d.logf("Writing readiness file: %v\n", err)
+	}
+}
+
+// removeReadinessFile removes the readiness file, if one is set
+func (d *Dexter) removeReadinessFile() {
+	if d.readinessPath == "" {
+		return
+	}
+	if err := os.Remove(d.readinessPath); err != nil && !os.IsNotExist(err) {
+		d.logf("Removing readiness file: %v\n", err)
+	}
+}
@@ -0,0 +1,34 @@
+package dexter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestReadinessFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ready")
+	if err := ioutil.WriteFile(path, []byte("stale\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dex := NewDexter(WithManualTrigger(), WithReadinessFile(path))
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("stale readiness file kept: %v", err)
+	}
+
+	dex.Ready()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != strconv.Itoa(os.Getpid())+"\n" {
+		t.Errorf("readiness file holds %q", data)
+	}
+
+	dex.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("readiness file left after shutdown: %v", err)
+	}
+}
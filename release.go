@@ -0,0 +1,82 @@
+package dexter
+
+import "time"
+
+// ResourceKind identifies the kind of resource a target releases
+type ResourceKind int
+
+const (
+	// ResourceFunc is a function run on kill, such as a context.CancelFunc
+	ResourceFunc ResourceKind = iota
+	// ResourceCloser is an io.Closer
+	ResourceCloser
+	// ResourceChannel is a channel
+	ResourceChannel
+	// ResourceLock is a Lock
+	ResourceLock
+	// ResourceTx is a transaction rolled back by the target
+	ResourceTx
+)
+
+func (k ResourceKind) String() string {
+	switch k {
+	case ResourceFunc:
+		return "func"
+	case ResourceCloser:
+		return "closer"
+	case ResourceChannel:
+		return "channel"
+	case ResourceLock:
+		return "lock"
+	case ResourceTx:
+		return "transaction"
+	}
+	return "unknown"
+}
+
+// Release describes a single resource released by a target.  Resource is
+// the value that was tracked, it is nil for funcs.  Name is the name the
+// resource was tracked with by one of the Named variants, or its type, it
+// is empty for unnamed funcs.
+type Release struct {
+	Target   string
+	Kind     ResourceKind
+	Name     string
+	Resource interface{}
+	Err      error
+	At       time.Time
+}
+
+// OnRelease registers fn to be called, in order, for every resource the
+// target releases while it is killed
+func (t *Target) OnRelease(fn func(Release)) {
+	t.mu.Lock()
+	t.onRelease = append(t.onRelease, fn)
+	t.mu.Unlock()
+}
+
+func (t *Target) released(kind ResourceKind, name string, resource interface{}, err error) {
+	t.mu.Lock()
+	hooks := t.onRelease
+	t.mu.Unlock()
+	if len(hooks) == 0 {
+		return
+	}
+	r := Release{Target: t.name, Kind: kind, Name: name, Resource: resource, Err: err, At: time.Now()}
+	for _, fn := range hooks {
+		fn(r)
+	}
+}
+
+// wrapper is implemented by dexter's own closer wrappers so release hooks
+// see the resource the application tracked, not the wrapper
+type wrapper interface {
+	unwrap() interface{}
+}
+
+func unwrap(resource interface{}) interface{} {
+	if w, ok := resource.(wrapper); ok {
+		return w.unwrap()
+	}
+	return resource
+}
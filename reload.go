@@ -0,0 +1,127 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// Reloader applies configuration changes by rebuilding the targets they
+// affect, see Dexter.Reload
+type Reloader interface {
+	// Validate loads and checks the new configuration without applying it
+	Validate() error
+	// Affected returns the names of the tracked targets which have to be
+	// rebuilt for the new configuration
+	Affected() []string
+	// Build creates and starts the replacement targets, keyed by the name
+	// of the target each replaces.  An error means some of them failed to
+	// start, the ones returned anyway are killed.
+	Build() (map[string]*Target, error)
+}
+
+// SetReloader sets r as the reloader used by Reload.  On Unix SIGHUP
+// triggers a reload of the root Dexter from then on, across restarts, until
+// it is closed or releases its signals.
+func (d *Dexter) SetReloader(r Reloader) {
+	d.mu.Lock()
+	first := d.reloader == nil
+	d.reloader = r
+	d.mu.Unlock()
+	if first && !d.manual && len(reloadSignals) > 0 {
+		hup := make(chan os.Signal, 1)
+		signalOwner.Lock()
+		d.reloads = hup
+		signal.Notify(hup, reloadSignals...)
+		signalOwner.Unlock()
+		go d.reloadOnSignal(hup)
+	}
+}
+
+// reloadOnSignal reloads on every signal on hup.  Stopping is closed by
+// Restart too, so it lives as long as the Dexter instead.
+func (d *Dexter) reloadOnSignal(hup chan os.Signal) {
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-hup:
+			if err := d.Reload(); err != nil {
+				d.logf("Reload failed: %v\n", err)
+			}
+		case <-d.closed:
+			return
+		}
+	}
+}
+
+// Reload validates the new configuration, quiesces only the affected
+// targets, builds their replacements and swaps them in with Replace before
+// killing the old ones.  If validation fails nothing is touched, if any
+// replacement fails to start the old targets are resumed and stay tracked.
+func (d *Dexter) Reload() (err error) {
+	d.mu.Lock()
+	r := d.reloader
+	d.mu.Unlock()
+	if r == nil {
+		return fmt.Errorf("reload: no reloader set")
+	}
+	defer func(started time.Time) { d.record("reload", started, err, nil) }(time.Now())
+	// reloads must not race a shutdown or restart
+	d.cycle.Lock()
+	defer d.cycle.Unlock()
+
+	d.logf("Reloading\n")
+	if err := r.Validate(); err != nil {
+		return fmt.Errorf("reload: invalid configuration: %w", err)
+	}
+
+	var old []*Target
+	for _, name := range r.Affected() {
+		d.mu.Lock()
+		i := d.indexOf(name)
+		if i < 0 {
+			d.mu.Unlock()
+			return fmt.Errorf("reload: no target named %q is tracked", name)
+		}
+		old = append(old, d.targets[i])
+		d.mu.Unlock()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.forceKillInterval())
+	defer cancel()
+	var failed []string
+	for _, target := range old {
+		failed = append(failed, target.quiesce(ctx)...)
+	}
+
+	built, err := r.Build()
+	if err == nil && len(failed) > 0 {
+		err = fmt.Errorf("quiesce: %s", strings.Join(failed, "; "))
+	}
+	for _, target := range old {
+		if _, ok := built[target.name]; err == nil && !ok {
+			err = fmt.Errorf("no replacement built for %q", target.name)
+		}
+	}
+	if err != nil {
+		for _, target := range built {
+			target.Kill()
+		}
+		for _, target := range old {
+			target.resume()
+		}
+		return fmt.Errorf("reload: rolled back: %w", err)
+	}
+
+	for _, target := range old {
+		if _, err := d.Replace(target.name, built[target.name]); err != nil {
+			return fmt.Errorf("reload: %w", err)
+		}
+		killTarget(target, d.limitFor(target), nil)
+	}
+	d.logf("Reloaded %d targets\n", len(old))
+	return nil
+}
@@ -0,0 +1,98 @@
+package dexter
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeReloader struct {
+	affected []string
+	build    func() (map[string]*Target, error)
+	invalid  error
+}
+
+func (r *fakeReloader) Validate() error                    { return r.invalid }
+func (r *fakeReloader) Affected() []string                 { return r.affected }
+func (r *fakeReloader) Build() (map[string]*Target, error) { return r.build() }
+
+func TestReloadSwapsAffectedTargets(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	db, cache := NewTarget("db"), NewTarget("cache")
+	dex.Track(db)
+	dex.Track(cache)
+	inflight := NewInFlight(cache)
+
+	fresh := NewTarget("cache")
+	dex.SetReloader(&fakeReloader{
+		affected: []string{"cache"},
+		build: func() (map[string]*Target, error) {
+			if _, ok := inflight.Begin(); ok {
+				t.Error("old target accepted work while its replacement was built")
+			}
+			return map[string]*Target{"cache": fresh}, nil
+		},
+	})
+	if err := dex.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cache.State() != TargetStopped {
+		t.Errorf("replaced target is %v", cache.State())
+	}
+	if db.State() != TargetRunning {
+		t.Errorf("unaffected target is %v", db.State())
+	}
+	order := dex.killOrder()
+	if len(order) != 2 || order[1] != fresh {
+		t.Errorf("replacement not tracked in place: %v", order)
+	}
+}
+
+func TestReloadRollsBack(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	cache := NewTarget("cache")
+	dex.Track(cache)
+	inflight := NewInFlight(cache)
+
+	partial := NewTarget("cache")
+	dex.SetReloader(&fakeReloader{
+		affected: []string{"cache"},
+		build: func() (map[string]*Target, error) {
+			return map[string]*Target{"cache": partial}, errors.New("listen: address in use")
+		},
+	})
+	if err := dex.Reload(); err == nil {
+		t.Fatal("failed build was not reported")
+	}
+
+	if partial.State() != TargetStopped {
+		t.Errorf("partially built target is %v", partial.State())
+	}
+	if cache.State() != TargetRunning {
+		t.Errorf("old target is %v", cache.State())
+	}
+	if done, ok := inflight.Begin(); !ok {
+		t.Error("old target not resumed")
+	} else {
+		done()
+	}
+	if order := dex.killOrder(); len(order) != 1 || order[0] != cache {
+		t.Error("old target no longer tracked")
+	}
+}
+
+func TestReloadInvalidConfig(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.Track(NewTarget("cache"))
+	dex.SetReloader(&fakeReloader{
+		affected: []string{"cache"},
+		invalid:  errors.New("bad port"),
+		build: func() (map[string]*Target, error) {
+			t.Error("built an invalid configuration")
+			return nil, nil
+		},
+	})
+	if err := dex.Reload(); err == nil {
+		t.Fatal("invalid configuration was not reported")
+	}
+}
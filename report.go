@@ -0,0 +1,120 @@
+package dexter
+
+import (
+	"os"
+	"time"
+)
+
+// Report summarizes a shutdown.  Signal is nil when shutdown was started
+// with Shutdown, Reason is the signal's name or the reason passed to it.
+// Errors holds the errors which don't belong to any target, such as gates
+// which failed.  Uptime is how long the Dexter had been running when
+// shutdown started, EarlyTermination is set when the signal arrived before
+// the minimum uptime, see WithMinUptime.  ExitCode is the code set for
+// Reason with SetExitCode, or the one of the HookErrorPolicy.  Causes
+// lists Reason and every other cause which arrived while the shutdown ran,
+// see SetCausePriority.
+type Report struct {
+	Signal   os.Signal
+	Reason   string
+	Plan     string
+	Started  time.Time
+	Duration time.Duration
+	Targets  []TargetReport
+	Errors   []error
+
+	Uptime           time.Duration
+	EarlyTermination bool
+	ExitCode         int
+	Causes           []string
+
+	// restart is set for the report of Restart's shutdown
+	restart bool
+}
+
+// TargetReport summarizes the shutdown of a single target.  Errors holds
+// everything its closers, locks and goroutines returned, Overrun is set
+// when it took longer than its deadline and Skipped when the kill plan left
+// it out.
+// Details are contributed by closers implementing ReportDetailer.  Running
+// names the goroutines started with GoNamed which still hadn't returned
+// at the end of the shutdown.  Description and Owner are the target's.
+type TargetReport struct {
+	Name     string
+	Duration time.Duration
+	Errors   []error
+	Overrun  bool
+	Skipped  bool
+	Details  map[string]string
+	Running  []string
+
+	Description string
+	Owner       string
+}
+
+// ReportDetailer is implemented by closers which have more to say about
+// their shutdown than an error, ReportDetails is called after Close
+type ReportDetailer interface {
+	ReportDetails() map[string]string
+}
+
+// Clean reports whether every target shut down in time and without errors
+func (r *Report) Clean() bool {
+	if len(r.Errors) > 0 {
+		return false
+	}
+	for _, target := range r.Targets {
+		if target.Overrun || len(target.Errors) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// LastReport returns the report of the last completed shutdown, nil if
+// there hasn't been one
+func (d *Dexter) LastReport() *Report {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.report
+}
+
+// reportSoFar returns a copy of the report of the shutdown in progress,
+// nil if there is none
+func (d *Dexter) reportSoFar() *Report {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.current == nil {
+		return nil
+	}
+	report := *d.current
+	report.Targets = append([]TargetReport(nil), d.current.Targets...)
+	report.Duration = time.Since(report.Started)
+	return &report
+}
+
+// details collects the report details of the target's closers
+func (t *Target) details() map[string]string {
+	t.mu.Lock()
+	monitored, adopted := t.monitored, t.adopted
+	t.mu.Unlock()
+
+	var details map[string]string
+	add := func(more map[string]string) {
+		for k, v := range more {
+			if details == nil {
+				details = map[string]string{}
+			}
+			details[k] = v
+		}
+	}
+	for _, closer := range monitored {
+		if detailer, ok := closer.(ReportDetailer); ok {
+			add(detailer.ReportDetails())
+		}
+	}
+	for _, other := range adopted {
+		add(other.details())
+	}
+	return details
+}
@@ -0,0 +1,78 @@
+package dexter
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+var (
+	// ErrChannelClosed is returned for closing a channel which is already
+	// closed
+	ErrChannelClosed = errors.New("dexter: close of closed channel")
+	// ErrNilChannel is returned for closing a nil channel
+	ErrNilChannel = errors.New("dexter: close of nil channel")
+)
+
+// SafeClose closes ch, returning ErrChannelClosed or ErrNilChannel instead
+// of panicking when it was already closed or is nil
+func SafeClose[T any](ch chan T) (err error) {
+	if ch == nil {
+		return ErrNilChannel
+	}
+	defer recoverClose(&err)
+	close(ch)
+	return nil
+}
+
+// safeCloseValue is SafeClose for channels of any type, as tracked by
+// TrackChannel
+func safeCloseValue(channel interface{}) (err error) {
+	v := reflect.ValueOf(channel)
+	if v.IsNil() {
+		return ErrNilChannel
+	}
+	defer recoverClose(&err)
+	v.Close()
+	return nil
+}
+
+// recoverClose turns the panic of closing a closed channel into an error
+func recoverClose(err *error) {
+	if r := recover(); r != nil {
+		*err = ErrChannelClosed
+	}
+}
+
+// CloseOnce wraps a channel so it can be closed any number of times from
+// anywhere, only the first Close closes it.  It is an io.Closer, so it can
+// be tracked with TrackCloser.
+type CloseOnce[T any] struct {
+	ch   chan T
+	once sync.Once
+	err  error
+}
+
+// NewCloseOnce wraps ch
+func NewCloseOnce[T any](ch chan T) *CloseOnce[T] {
+	return &CloseOnce[T]{ch: ch}
+}
+
+// Chan returns the wrapped channel
+func (c *CloseOnce[T]) Chan() chan T {
+	return c.ch
+}
+
+// Close closes the channel the first time it is called, later calls
+// return the same result.  It returns ErrChannelClosed if the channel was
+// closed without going through the wrapper.
+func (c *CloseOnce[T]) Close() error {
+	c.once.Do(func() {
+		c.err = SafeClose(c.ch)
+	})
+	return c.err
+}
+
+func (c *CloseOnce[T]) unwrap() interface{} {
+	return c.ch
+}
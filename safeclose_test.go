@@ -0,0 +1,41 @@
+package dexter
+
+import "testing"
+
+func TestSafeClose(t *testing.T) {
+	ch := make(chan int)
+	if err := SafeClose(ch); err != nil {
+		t.Fatal(err)
+	}
+	if err := SafeClose(ch); err != ErrChannelClosed {
+		t.Errorf("second close returned %v", err)
+	}
+	if err := SafeClose[int](nil); err != ErrNilChannel {
+		t.Errorf("closing nil returned %v", err)
+	}
+}
+
+func TestCloseOnce(t *testing.T) {
+	c := NewCloseOnce(make(chan string))
+	target := NewTarget("once")
+	target.TrackCloser(c)
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if errs := target.kill(); len(errs) > 0 {
+		t.Errorf("closing twice through the wrapper failed: %v", errs)
+	}
+	if _, ok := <-c.Chan(); ok {
+		t.Error("channel not closed")
+	}
+}
+
+func TestKillReportsClosedChannel(t *testing.T) {
+	target := NewTarget("closed")
+	ch := make(chan int)
+	target.TrackChannel(ch)
+	close(ch)
+	if errs := target.kill(); len(errs) != 1 || errs[0] != ErrChannelClosed {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
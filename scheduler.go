@@ -0,0 +1,106 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scheduler is implemented by cron style schedulers such as robfig/cron.
+// Stop stops scheduling new runs and returns a context which is done once
+// the jobs already running have completed.
+type Scheduler interface {
+	Stop() context.Context
+}
+
+// AbandonedJobsError is returned by SchedulerCloser when jobs are still
+// running after its timeout, or once the shutdown's context is done,
+// Jobs lists the ones wrapped with Wrap and Timeout is how long it waited
+type AbandonedJobsError struct {
+	Jobs    []string
+	Timeout time.Duration
+}
+
+func (e *AbandonedJobsError) Error() string {
+	if len(e.Jobs) == 0 {
+		return fmt.Sprintf("scheduler: in-flight jobs abandoned after %v", e.Timeout)
+	}
+	return fmt.Sprintf("scheduler: %d in-flight jobs abandoned after %v: %s",
+		len(e.Jobs), e.Timeout, strings.Join(e.Jobs, ", "))
+}
+
+// SchedulerCloser adapts a Scheduler to io.Closer so it can be handed to
+// TrackCloser.  Closing stops the scheduler and waits up to timeout for
+// in-flight jobs.  Wrap jobs when adding them to the scheduler to have the
+// abandoned ones named in the returned error.
+type SchedulerCloser struct {
+	scheduler Scheduler
+	timeout   time.Duration
+
+	mu      sync.Mutex
+	running map[string]int
+}
+
+// NewSchedulerCloser returns a closer stopping scheduler, waiting up to
+// timeout for running jobs
+func NewSchedulerCloser(scheduler Scheduler, timeout time.Duration) *SchedulerCloser {
+	return &SchedulerCloser{
+		scheduler: scheduler,
+		timeout:   timeout,
+		running:   map[string]int{},
+	}
+}
+
+// Wrap returns job instrumented so the closer knows when a job called name
+// is running, e.g. c.AddFunc("@hourly", sc.Wrap("report", report))
+func (s *SchedulerCloser) Wrap(name string, job func()) func() {
+	return func() {
+		s.mu.Lock()
+		s.running[name]++
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			if s.running[name]--; s.running[name] == 0 {
+				delete(s.running, name)
+			}
+			s.mu.Unlock()
+		}()
+		job()
+	}
+}
+
+// Close stops the scheduler and waits for in-flight jobs, it returns an
+// *AbandonedJobsError if they didn't finish in time
+func (s *SchedulerCloser) Close() error {
+	return s.CloseWithContext(context.Background())
+}
+
+// CloseWithContext is Close, waiting until the timeout or ctx ends,
+// whichever comes first
+func (s *SchedulerCloser) CloseWithContext(ctx context.Context) error {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	stopped := s.scheduler.Stop()
+	select {
+	case <-stopped.Done():
+		return nil
+	case <-ctx.Done():
+	}
+	waited := time.Since(start)
+	if waited > s.timeout {
+		waited = s.timeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var jobs []string
+	for name := range s.running {
+		jobs = append(jobs, name)
+	}
+	sort.Strings(jobs)
+	return &AbandonedJobsError{Jobs: jobs, Timeout: waited}
+}
@@ -0,0 +1,46 @@
+package dexter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// stuckScheduler never reports its running jobs as finished
+type stuckScheduler struct{}
+
+func (stuckScheduler) Stop() context.Context {
+	return context.Background()
+}
+
+func TestSchedulerCloser(t *testing.T) {
+	sc := NewSchedulerCloser(stuckScheduler{}, 20*time.Millisecond)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go sc.Wrap("stuck", func() {
+		close(started)
+		<-release
+	})()
+	<-started
+	defer close(release)
+
+	err, ok := sc.Close().(*AbandonedJobsError)
+	if !ok || len(err.Jobs) != 1 || err.Jobs[0] != "stuck" {
+		t.Fatalf("expected stuck job to be abandoned, got %v", err)
+	}
+}
+
+func TestSchedulerCloserHonoursContext(t *testing.T) {
+	sc := NewSchedulerCloser(stuckScheduler{}, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, ok := sc.CloseWithContext(ctx).(*AbandonedJobsError); !ok {
+		t.Fatal("expected the jobs to be abandoned")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %v past the context", elapsed)
+	}
+}
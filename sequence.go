@@ -0,0 +1,191 @@
+package dexter
+
+// Step is a resource released by a shutdown, see KillSequence
+type Step struct {
+	Target string
+	Kind   ResourceKind
+	Name   string
+}
+
+// Steps iterates over the steps of a kill sequence:
+//
+//	steps := dex.KillSequence()
+//	for steps.Next() {
+//		fmt.Println(steps.Step())
+//	}
+type Steps struct {
+	steps []Step
+	i     int
+}
+
+// Next advances to the next step, it returns false after the last one
+func (s *Steps) Next() bool {
+	if s.i >= len(s.steps) {
+		return false
+	}
+	s.i++
+	return true
+}
+
+// Step returns the current step
+func (s *Steps) Step() Step {
+	return s.steps[s.i-1]
+}
+
+// KillSequence returns the resources a graceful shutdown would release
+// right now, in the order it is guaranteed to release them, so tests can
+// pin down the ordering an application depends on.  Targets are killed in
+// kill order, see Targets.  Within a target released with CloseThenWait:
+// funcs, transactions, closers, channels, then the targets it adopted the
+// same way, and once the target drained its locks, then those of adopted
+// targets.  With WaitThenClose channels come right after the funcs, the
+// target drains, then transactions and closers follow.  WithPipeline first
+// runs the funcs and closes the channels of every target, in kill order.
+//
+// Funcs, closers, channels and locks are released in the order they were
+// tracked, transactions in no particular order.  A closer shared with a
+// target released earlier is left out.  Names are those of Release.
+// Targets which KillIf would have shutdown skip are left out, their
+// predicates are called to find out.
+func (d *Dexter) KillSequence() *Steps {
+	var targets []*Target
+	for _, target := range d.killOrder() {
+		if target.wanted() {
+			targets = append(targets, target)
+		}
+	}
+	seq := newSequence()
+	if d.pipeline {
+		for _, target := range targets {
+			if target.State() == TargetRunning {
+				seq.intake(target)
+			}
+		}
+	}
+	for _, target := range targets {
+		seq.target(target)
+	}
+	return &Steps{steps: seq.steps}
+}
+
+// KillSequence returns the resources Kill would release right now, in
+// order, see Dexter.KillSequence
+func (t *Target) KillSequence() *Steps {
+	seq := newSequence()
+	seq.target(t)
+	return &Steps{steps: seq.steps}
+}
+
+// sequence builds a kill sequence, closers holds the closers and intakes
+// the targets whose funcs and channels are already in it
+type sequence struct {
+	steps   []Step
+	closers map[interface{}]bool
+	intakes map[*Target]bool
+}
+
+func newSequence() *sequence {
+	return &sequence{closers: map[interface{}]bool{}, intakes: map[*Target]bool{}}
+}
+
+func (s *sequence) add(target *Target, kind ResourceKind, name string) {
+	s.steps = append(s.steps, Step{Target: target.name, Kind: kind, Name: name})
+}
+
+// target adds the steps of killing target
+func (s *sequence) target(target *Target) {
+	if target.State() != TargetRunning {
+		return
+	}
+	s.release(target)
+	s.locks(target)
+}
+
+// release adds everything killContext releases, adopted targets included
+func (s *sequence) release(target *Target) {
+	if target.ClosePolicy() == WaitThenClose {
+		s.intake(target)
+		s.txs(target)
+		s.closersOf(target)
+	} else {
+		s.funcs(target)
+		s.txs(target)
+		s.closersOf(target)
+		s.channels(target)
+	}
+	target.mu.Lock()
+	adopted := target.adopted
+	target.mu.Unlock()
+	for _, other := range adopted {
+		if other.State() == TargetRunning {
+			s.release(other)
+		}
+	}
+}
+
+// intake adds running the target's funcs and closing its channels
+func (s *sequence) intake(target *Target) {
+	s.funcs(target)
+	s.channels(target)
+}
+
+func (s *sequence) funcs(target *Target) {
+	target.mu.Lock()
+	funcs, done := target.funcs, target.funcsRun || target.intakeClosed
+	target.mu.Unlock()
+	if done || s.intakes[target] {
+		return
+	}
+	for _, f := range funcs {
+		s.add(target, ResourceFunc, f.name)
+	}
+}
+
+func (s *sequence) channels(target *Target) {
+	target.mu.Lock()
+	channels, done := target.channels, target.intakeClosed
+	target.mu.Unlock()
+	if done || s.intakes[target] {
+		return
+	}
+	for _, channel := range channels {
+		s.add(target, ResourceChannel, target.channelName(channel))
+	}
+	s.intakes[target] = true
+}
+
+func (s *sequence) txs(target *Target) {
+	target.mu.Lock()
+	defer target.mu.Unlock()
+	for key := range target.txs {
+		s.steps = append(s.steps, Step{Target: target.name, Kind: ResourceTx, Name: resourceName(*key)})
+	}
+}
+
+func (s *sequence) closersOf(target *Target) {
+	target.mu.Lock()
+	monitored := target.monitored
+	target.mu.Unlock()
+	for _, closer := range monitored {
+		if key := unwrap(closer); hashable(key) {
+			if s.closers[key] {
+				continue
+			}
+			s.closers[key] = true
+		}
+		s.add(target, ResourceCloser, resourceName(closer))
+	}
+}
+
+// locks adds releasing the target's locks, then those of adopted targets
+func (s *sequence) locks(target *Target) {
+	target.mu.Lock()
+	locks, adopted := target.locks, target.adopted
+	target.mu.Unlock()
+	for _, lock := range locks {
+		s.add(target, ResourceLock, resourceName(lock.Lock))
+	}
+	for _, other := range adopted {
+		s.locks(other)
+	}
+}
@@ -0,0 +1,73 @@
+package dexter
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestKillSequence(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	shared := &countingCloser{}
+
+	ingress := NewTarget("ingress")
+	ingress.TrackCancelNamed("listener", func() {})
+	ingress.TrackChannelNamed("requests", make(chan int))
+	ingress.TrackCloserNamed("server", closerFunc(func() error { return nil }))
+	ingress.TrackCloser(shared)
+	ingress.TrackTx(&fakeTx{})
+
+	cache := NewTarget("cache")
+	cache.TrackCloserNamed("memcache", closerFunc(func() error { return nil }))
+	workers := NewTarget("workers")
+	workers.SetClosePolicy(WaitThenClose)
+	workers.TrackCloserNamed("db", closerFunc(func() error { return nil }))
+	workers.TrackCloser(shared)
+	workers.TrackChannelNamed("jobs", make(chan int))
+	workers.TrackCancelNamed("ctx", func() {})
+	workers.TrackLock(LockFile(filepath.Join(t.TempDir(), "lock")))
+	workers.Adopt(cache)
+
+	skipped := NewTarget("skipped")
+	skipped.TrackCloserNamed("flagged", closerFunc(func() error { return nil }))
+	skipped.KillIf(func() bool { return false })
+
+	dex.Track(workers)
+	dex.Track(skipped)
+	dex.TrackPhase(PhaseIngress, ingress)
+
+	want := []Step{
+		{"ingress", ResourceFunc, "listener"},
+		{"ingress", ResourceTx, "*dexter.fakeTx"},
+		{"ingress", ResourceCloser, "server"},
+		{"ingress", ResourceCloser, "*dexter.countingCloser"},
+		{"ingress", ResourceChannel, "requests"},
+		{"workers", ResourceFunc, "ctx"},
+		{"workers", ResourceChannel, "jobs"},
+		{"workers", ResourceCloser, "db"},
+		{"cache", ResourceCloser, "memcache"},
+		{"workers", ResourceLock, "dexter.lockFile"},
+	}
+	var got []Step
+	for steps := dex.KillSequence(); steps.Next(); {
+		got = append(got, steps.Step())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("sequence\n%v\nwant\n%v", got, want)
+	}
+
+	// the shutdown must release exactly what the sequence promised
+	var released []Step
+	for _, target := range []*Target{ingress, workers, cache, skipped} {
+		target.OnRelease(func(r Release) {
+			released = append(released, Step{Target: r.Target, Kind: r.Kind, Name: r.Name})
+		})
+	}
+	dex.Close()
+	if !reflect.DeepEqual(released, want) {
+		t.Errorf("released\n%v\nwant\n%v", released, want)
+	}
+	if dex.KillSequence().Next() {
+		t.Error("killed targets still have steps")
+	}
+}
@@ -0,0 +1,136 @@
+package dexter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ShardSet is a Target managing shards which are targets of their own.
+// Shards are killed in order, in waves of up to wave shards in parallel,
+// each wave has to stop before the next starts so shard handoff can be
+// sequenced.  Per shard durations are added to the shutdown report.
+type ShardSet struct {
+	*Target
+	wave int
+
+	mu        sync.Mutex
+	shards    []*Target
+	durations map[string]time.Duration
+}
+
+// NewShardSet returns a shard set killing shards in the given order, wave
+// shards at a time.  A wave of 1 kills them strictly one after the other.
+func NewShardSet(name string, wave int, shards ...*Target) *ShardSet {
+	if wave < 1 {
+		wave = 1
+	}
+	s := &ShardSet{
+		Target:    NewTarget(name),
+		wave:      wave,
+		shards:    shards,
+		durations: map[string]time.Duration{},
+	}
+	s.TrackCloser(shardCloser{s})
+	return s
+}
+
+// Reorder changes the kill order to the shards named, shards which are
+// not named keep their relative order after the named ones
+func (s *ShardSet) Reorder(names ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ordered := make([]*Target, 0, len(s.shards))
+	used := map[*Target]bool{}
+	for _, name := range names {
+		var found *Target
+		for _, shard := range s.shards {
+			if shard.name == name && !used[shard] {
+				found = shard
+				break
+			}
+		}
+		if found == nil {
+			return fmt.Errorf("no shard named %q", name)
+		}
+		used[found] = true
+		ordered = append(ordered, found)
+	}
+	for _, shard := range s.shards {
+		if !used[shard] {
+			ordered = append(ordered, shard)
+		}
+	}
+	s.shards = ordered
+	return nil
+}
+
+// Durations returns how long each shard took to shut down
+func (s *ShardSet) Durations() map[string]time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	durations := make(map[string]time.Duration, len(s.durations))
+	for name, d := range s.durations {
+		durations[name] = d
+	}
+	return durations
+}
+
+// shardCloser kills the shards wave by wave
+type shardCloser struct {
+	s *ShardSet
+}
+
+func (c shardCloser) Close() error {
+	s := c.s
+	s.mu.Lock()
+	shards := s.shards
+	s.mu.Unlock()
+
+	var failed []string
+	for i := 0; i < len(shards); i += s.wave {
+		end := i + s.wave
+		if end > len(shards) {
+			end = len(shards)
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		for _, shard := range shards[i:end] {
+			wg.Add(1)
+			go func(shard *Target) {
+				defer wg.Done()
+				start := time.Now()
+				errs, overrun := killTarget(shard, limit{}, nil)
+
+				mu.Lock()
+				defer mu.Unlock()
+				s.mu.Lock()
+				s.durations[shard.name] = time.Since(start)
+				s.mu.Unlock()
+				if overrun {
+					failed = append(failed, shard.name+": overran its deadline")
+				}
+				for _, err := range errs {
+					failed = append(failed, shard.name+": "+err.Error())
+				}
+			}(shard)
+		}
+		wg.Wait()
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("shards failed to shut down cleanly: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// ReportDetails adds per shard durations to the shutdown report
+func (c shardCloser) ReportDetails() map[string]string {
+	details := map[string]string{}
+	for name, d := range c.s.Durations() {
+		details["shard."+name+".duration"] = d.String()
+	}
+	return details
+}
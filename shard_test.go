@@ -0,0 +1,46 @@
+package dexter
+
+import (
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestShardSetWaves(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	shard := func(name string) *Target {
+		target := NewTarget(name)
+		target.TrackCancel(func() {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		})
+		return target
+	}
+
+	set := NewShardSet("shards", 2, shard("s0"), shard("s1"), shard("s2"), shard("s3"), shard("s4"))
+	if err := set.Reorder("s4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Reorder("missing"); err == nil {
+		t.Error("expected an error reordering an unknown shard")
+	}
+
+	dex := NewDexter(WithManualTrigger())
+	dex.Track(set.Target)
+	dex.SimulateSignal(os.Interrupt)
+	dex.WaitAndKill()
+
+	// waves are {s4, s0}, {s1, s2}, {s3}
+	wave := map[string]int{"s4": 0, "s0": 0, "s1": 1, "s2": 1, "s3": 2}
+	for i := 1; i < len(order); i++ {
+		if wave[order[i]] < wave[order[i-1]] {
+			t.Fatalf("shards killed out of wave order: %v", order)
+		}
+	}
+	details := dex.LastReport().Targets[0].Details
+	if len(details) != 5 || details["shard.s3.duration"] == "" {
+		t.Errorf("unexpected details %v", details)
+	}
+}
@@ -0,0 +1,158 @@
+package dexter
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// sharedClosers is a Dexter's registry of the closers its targets track,
+// so a closer tracked by several of them, such as a shared connection
+// pool, is closed exactly once, by whichever target is killed first.  Each
+// target keeps the sharedClose of its closers, the registry only holds it
+// while a target of the Dexter may still need to find it.
+type sharedClosers struct {
+	mu      sync.Mutex
+	closers map[interface{}]*sharedClose
+}
+
+// sharedClose is a closer of a registry and the targets referencing it
+type sharedClose struct {
+	once     sync.Once
+	refs     int
+	registry *sharedClosers
+}
+
+func newSharedClosers() *sharedClosers {
+	return &sharedClosers{closers: map[interface{}]*sharedClose{}}
+}
+
+// share records that closer has been tracked once more.  Only closers
+// referring to what they close, such as pointers, are recognized, two
+// equal values of a struct type may well be different closers, nil is
+// returned for the others.  Closers wrapped by dexter, e.g. to name them,
+// are recognized by what they wrap.
+func (r *sharedClosers) share(closer io.Closer) *sharedClose {
+	key := unwrap(closer)
+	if !byReference(key) {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.closers[key]
+	if s == nil {
+		s = &sharedClose{registry: r}
+		r.closers[key] = s
+	}
+	s.refs++
+	return s
+}
+
+// unref drops a reference to s, the closer key is forgotten once every
+// target tracking it got to it
+func (s *sharedClose) unref(key interface{}) {
+	r := s.registry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s.refs--; s.refs == 0 && r.closers[key] == s {
+		delete(r.closers, key)
+	}
+}
+
+// closeShared closes closer unless another target already did, first is
+// false when it was already handled.  shares are the sharedCloses of the
+// target closing it, unref is false when the target already gave up its
+// references, see unshare.
+func closeShared(ctx context.Context, closer io.Closer, shares map[interface{}]*sharedClose,
+	unref bool) (first bool, err error) {
+	key := unwrap(closer)
+	var s *sharedClose
+	if byReference(key) {
+		s = shares[key]
+	}
+	if s == nil {
+		return true, closeLimited(ctx, closer)
+	}
+	if unref {
+		s.unref(key)
+	}
+	s.once.Do(func() {
+		first, err = true, closeLimited(ctx, closer)
+	})
+	return first, err
+}
+
+// join shares the target's closers through r, the registry of the Dexter
+// now tracking it, giving up its references in the registry of the one
+// which tracked it before.  Adopted targets join as well.
+func (t *Target) join(r *sharedClosers) {
+	t.mu.Lock()
+	if t.shared == r && !t.unshared {
+		t.mu.Unlock()
+		return
+	}
+	monitored, shares, adopted := t.monitored, t.shares, t.adopted
+	left := t.shared != nil && !t.unshared
+	t.shared, t.unshared = r, false
+	t.shares = map[interface{}]*sharedClose{}
+	for _, closer := range monitored {
+		if s := r.share(closer); s != nil {
+			t.shares[unwrap(closer)] = s
+		}
+	}
+	t.mu.Unlock()
+	if left {
+		unrefAll(monitored, shares)
+	}
+	for _, other := range adopted {
+		other.join(r)
+	}
+}
+
+// unshare gives up the target's references to its shared closers, for
+// targets their Dexter let go of without killing them, so the registry
+// doesn't keep them forever.  Killing the target later still closes each
+// closer at most once.  Adopted targets are let go of as well.
+func (t *Target) unshare() {
+	t.mu.Lock()
+	done := t.unshared
+	t.unshared = true
+	monitored, shares, adopted := t.monitored, t.shares, t.adopted
+	t.mu.Unlock()
+	if !done {
+		unrefAll(monitored, shares)
+	}
+	for _, other := range adopted {
+		other.unshare()
+	}
+}
+
+// unrefAll drops the references shares hold to the closers of monitored
+func unrefAll(monitored []io.Closer, shares map[interface{}]*sharedClose) {
+	for _, closer := range monitored {
+		if key := unwrap(closer); byReference(key) && shares[key] != nil {
+			shares[key].unref(key)
+		}
+	}
+}
+
+// hashable reports whether v can be used as a map key
+func hashable(v interface{}) bool {
+	typ := reflect.TypeOf(v)
+	return typ != nil && typ.Comparable()
+}
+
+// byReference reports whether v refers to what it closes, so two equal
+// values are the same closer
+func byReference(v interface{}) bool {
+	typ := reflect.TypeOf(v)
+	if typ == nil {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package dexter
+
+import (
+	"errors"
+	"testing"
+)
+
+type countingCloser struct {
+	closes int
+}
+
+func (c *countingCloser) Close() error {
+	c.closes++
+	if c.closes > 1 {
+		return errors.New("already closed")
+	}
+	return nil
+}
+
+func TestSharedCloserClosedOnce(t *testing.T) {
+	pool := &countingCloser{}
+	dex := NewDexter(WithManualTrigger())
+	first, second := NewTarget("first"), NewTarget("second")
+	dex.Track(first)
+	first.TrackCloser(pool)
+	second.TrackCloser(pool)
+	dex.Track(second)
+
+	var releases []Release
+	second.OnRelease(func(r Release) { releases = append(releases, r) })
+	if errs := first.kill(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if errs := second.kill(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	if pool.closes != 1 {
+		t.Errorf("shared closer closed %d times", pool.closes)
+	}
+	if len(releases) != 0 {
+		t.Errorf("already closed closer released again: %v", releases)
+	}
+	if recorded(dex, pool) {
+		t.Error("shared closer still recorded after every target closed it")
+	}
+}
+
+// recorded reports whether closer is in dex's registry of shared closers
+func recorded(dex *Dexter, closer interface{}) bool {
+	dex.shared.mu.Lock()
+	defer dex.shared.mu.Unlock()
+	_, ok := dex.shared.closers[closer]
+	return ok
+}
+
+func TestSharedCloserForgottenWithSkippedTarget(t *testing.T) {
+	pool := &countingCloser{}
+	dex := NewDexter(WithManualTrigger())
+	skipped := NewTarget("skipped")
+	skipped.TrackCloser(pool)
+	skipped.KillIf(func() bool { return false })
+	dex.Track(skipped)
+	other := NewTarget("other")
+	other.TrackCloser(pool)
+	other.KillIf(func() bool { return false })
+	dex.Track(other)
+
+	dex.Close()
+	if pool.closes != 0 {
+		t.Fatalf("skipped target closed its closer")
+	}
+	other.Kill()
+	if recorded(dex, pool) {
+		t.Error("closer of a target skipped by shutdown still recorded")
+	}
+	// killing the skipped target later doesn't close the pool again
+	skipped.Kill()
+	if pool.closes != 1 {
+		t.Errorf("shared closer closed %d times", pool.closes)
+	}
+}
+
+// valueCloser is a closer compared by value
+type valueCloser struct {
+	closes *int
+}
+
+func (c valueCloser) Close() error {
+	*c.closes++
+	return nil
+}
+
+func TestSharedClosersScopedToDexter(t *testing.T) {
+	pool := &countingCloser{}
+	first, second := NewDexter(WithManualTrigger()), NewDexter(WithManualTrigger())
+	for _, dex := range []*Dexter{first, second} {
+		target := NewTarget("db")
+		target.TrackCloser(pool)
+		dex.Track(target)
+	}
+	first.Close()
+	second.Close()
+	if pool.closes != 2 {
+		t.Errorf("closer of unrelated dexters closed %d times, want 2", pool.closes)
+	}
+}
+
+func TestSharedClosersOnlyByReference(t *testing.T) {
+	closes := 0
+	dex := NewDexter(WithManualTrigger())
+	for _, name := range []string{"first", "second"} {
+		target := NewTarget(name)
+		target.TrackCloser(valueCloser{closes: &closes})
+		dex.Track(target)
+	}
+	dex.Close()
+	if closes != 2 {
+		t.Errorf("equal value closers closed %d times, want 2", closes)
+	}
+}
+
+func TestReplacedTargetUnshared(t *testing.T) {
+	pool := &countingCloser{}
+	dex := NewDexter(WithManualTrigger())
+	old := NewTarget("db")
+	old.TrackCloser(pool)
+	dex.Track(old)
+	if !recorded(dex, pool) {
+		t.Fatal("closer not recorded once tracked")
+	}
+
+	if _, err := dex.Replace("db", NewTarget("db")); err != nil {
+		t.Fatal(err)
+	}
+	if recorded(dex, pool) {
+		t.Error("closer of a replaced target still recorded")
+	}
+}
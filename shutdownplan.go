@@ -0,0 +1,162 @@
+package dexter
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+	"time"
+)
+
+// ShutdownPlan describes what a graceful shutdown would do right now:
+// the targets in kill order with the deadlines they are held to.
+// WorstCase is the longest the shutdown can take, including the shutdown
+// delay, Bounded is false when a target can take arbitrarily long, then
+// WorstCase only covers the others.  ForceKillMode tells what the
+// ForceKill window is measured from.
+type ShutdownPlan struct {
+	ForceKill     time.Duration
+	ForceKillMode ForceKillMode
+	Delay         time.Duration
+	Targets       []PlannedTarget
+	WorstCase     time.Duration
+	Bounded       bool
+}
+
+// PlannedTarget is a target of a ShutdownPlan.  DeadlineFrom is "target"
+// when the deadline was set with Target.SetDeadline, "default" when it was
+// inherited from Dexter.SetDefaultDeadline and "none" when there is none.
+//
+// Budget is the longest the target can take: its deadline, twice that with
+// OverrunFallback, or else the duration estimated from past shutdowns, see
+// WithDurationHistory.  BudgetFrom is "deadline", "estimate" or "none"
+// when the target can take arbitrarily long.  Cumulative is the worst case
+// time from the start of the shutdown until the target is done.
+type PlannedTarget struct {
+	Name         string
+	Phase        Phase
+	Deadline     time.Duration
+	Policy       OverrunPolicy
+	DeadlineFrom string
+	Closers      []PlannedCloser
+	Budget       time.Duration
+	BudgetFrom   string
+	Cumulative   time.Duration
+}
+
+// PlannedCloser is a closer tracked with its own timeout, Type is its
+// name if it was tracked with one
+type PlannedCloser struct {
+	Type    string
+	Timeout time.Duration
+}
+
+// Plan returns the shutdown plan for the currently tracked targets, with
+// the effective deadline of each target and closer
+func (d *Dexter) Plan() *ShutdownPlan {
+	plan := &ShutdownPlan{
+		ForceKill:     d.forceKillInterval(),
+		ForceKillMode: d.forceKillMode,
+		Delay:         d.shutdownDelay,
+		Bounded:       true,
+	}
+	plan.WorstCase = plan.Delay
+	for _, target := range d.killOrder() {
+		lim := d.limitFor(target)
+		planned := PlannedTarget{
+			Name:         target.name,
+			Phase:        target.Phase(),
+			Deadline:     lim.deadline,
+			Policy:       lim.policy,
+			DeadlineFrom: lim.source,
+		}
+		target.mu.Lock()
+		for _, closer := range target.monitored {
+			if timed, ok := closer.(*timedCloser); ok {
+				planned.Closers = append(planned.Closers, PlannedCloser{
+					Type:    resourceName(timed.closer),
+					Timeout: timed.timeout,
+				})
+			}
+		}
+		target.mu.Unlock()
+		planned.Budget, planned.BudgetFrom = d.budget(target, lim)
+		if planned.BudgetFrom == "none" {
+			plan.Bounded = false
+		}
+		plan.WorstCase += planned.Budget
+		planned.Cumulative = plan.WorstCase
+		plan.Targets = append(plan.Targets, planned)
+	}
+	return plan
+}
+
+// budget returns the longest target can take when killed with lim
+func (d *Dexter) budget(target *Target, lim limit) (time.Duration, string) {
+	switch {
+	case lim.deadline > 0 && lim.policy == OverrunFallback:
+		return 2 * lim.deadline, "deadline"
+	case lim.deadline > 0 && lim.policy != OverrunWait:
+		return lim.deadline, "deadline"
+	}
+	d.mu.Lock()
+	estimate := d.estimate(target.name)
+	d.mu.Unlock()
+	if estimate > 0 {
+		return estimate, "estimate"
+	}
+	return 0, "none"
+}
+
+// warnPlanBudget warns when the worst case shutdown doesn't fit the force
+// kill window, before the first shutdown shows it the hard way
+func (d *Dexter) warnPlanBudget() {
+	plan := d.Plan()
+	if plan.ForceKillMode == ForceKillWholeShutdown && plan.WorstCase > plan.ForceKill+plan.Delay {
+		d.logf("Warning: the worst case shutdown takes %v, longer than the %v force kill window\n",
+			plan.WorstCase, plan.ForceKill)
+	}
+}
+
+// String renders the plan as a table
+func (p *ShutdownPlan) String() string {
+	var buf bytes.Buffer
+	if p.ForceKillMode == ForceKillWholeShutdown {
+		fmt.Fprintf(&buf, "force kill after %v\n", p.ForceKill)
+	} else {
+		fmt.Fprintf(&buf, "force kill after %v, %v\n", p.ForceKill, p.ForceKillMode)
+	}
+	if p.Delay > 0 {
+		fmt.Fprintf(&buf, "shutdown delay %v\n", p.Delay)
+	}
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tPHASE\tDEADLINE\tPOLICY\tFROM\tBUDGET\tCUMULATIVE")
+	for _, target := range p.Targets {
+		deadline := "-"
+		if target.Deadline > 0 {
+			deadline = target.Deadline.String()
+		}
+		budget := "unbounded"
+		if target.BudgetFrom != "none" {
+			budget = target.Budget.String()
+			if target.BudgetFrom == "estimate" {
+				budget += " (estimated)"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%v\t%s\t%v\t%s\t%s\t%v\n", target.Name, target.Phase, deadline, target.Policy,
+			target.DeadlineFrom, budget, target.Cumulative)
+		for _, closer := range target.Closers {
+			fmt.Fprintf(tw, "  %s\t\t%v\t\tcloser\t\t\n", closer.Type, closer.Timeout)
+		}
+	}
+	tw.Flush()
+	worst := p.WorstCase.String()
+	if !p.Bounded {
+		worst = "unbounded, at least " + worst
+	}
+	fmt.Fprintf(&buf, "worst case %s", worst)
+	if p.ForceKillMode == ForceKillWholeShutdown && p.WorstCase > p.ForceKill+p.Delay {
+		buf.WriteString(", exceeds the force kill window")
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
@@ -0,0 +1,48 @@
+package dexter
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlan(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.SetDefaultDeadline(time.Second, OverrunSkip)
+	target := NewTarget("db")
+	target.TrackCloserTimeout(dcloser{}, 100*time.Millisecond)
+	dex.TrackPhase(PhaseStorage, target)
+
+	plan := dex.Plan()
+	if len(plan.Targets) != 1 || plan.Targets[0].DeadlineFrom != "default" || len(plan.Targets[0].Closers) != 1 {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	if out := plan.String(); !strings.Contains(out, "db") || !strings.Contains(out, "100ms") {
+		t.Errorf("unexpected rendering:\n%s", out)
+	}
+}
+
+func TestPlanBudget(t *testing.T) {
+	dex := NewDexter(WithManualTrigger(), WithForceKillWindow(time.Second))
+	fallback, skip, open := NewTarget("fallback"), NewTarget("skip"), NewTarget("open")
+	fallback.SetDeadline(300*time.Millisecond, OverrunFallback)
+	skip.SetDeadline(500*time.Millisecond, OverrunSkip)
+	dex.Track(fallback)
+	dex.Track(skip)
+
+	plan := dex.Plan()
+	if plan.Targets[0].Budget != 600*time.Millisecond || plan.Targets[1].Cumulative != 1100*time.Millisecond {
+		t.Errorf("unexpected budgets %+v", plan.Targets)
+	}
+	if !plan.Bounded || plan.WorstCase != 1100*time.Millisecond {
+		t.Errorf("worst case %v, bounded %v", plan.WorstCase, plan.Bounded)
+	}
+	if out := plan.String(); !strings.Contains(out, "exceeds the force kill window") {
+		t.Errorf("overrun not shown:\n%s", out)
+	}
+
+	dex.Track(open)
+	if plan := dex.Plan(); plan.Bounded || plan.Targets[2].BudgetFrom != "none" {
+		t.Errorf("target without deadline is bounded: %+v", plan.Targets[2])
+	}
+}
@@ -0,0 +1,69 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DrainedMarker lets processes on the same host order their shutdowns.
+// The main process tracks it as a closer right after the targets which have
+// to drain first, closing it creates the marker file.  A sidecar gates its
+// own shutdown on that file with a FileGate, so it doesn't finish before
+// the main process drained.
+type DrainedMarker struct {
+	path string
+}
+
+// NewDrainedMarker returns a marker creating path when closed, a marker
+// left over from a previous run is removed
+func NewDrainedMarker(path string) (*DrainedMarker, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return &DrainedMarker{path: path}, nil
+}
+
+// Close atomically creates the marker file, it holds the process's PID
+func (m *DrainedMarker) Close() error {
+	tmp, err := ioutil.TempFile(filepath.Dir(m.path), ".dexter-drained")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(tmp, "%d\n", os.Getpid())
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), m.path)
+}
+
+// FileGate is a Gate which opens once a file exists, use it with the path
+// of another process's DrainedMarker
+type FileGate struct {
+	Path string
+	// Poll is how often to check for the file
+	Poll time.Duration
+}
+
+// NewFileGate returns a gate waiting for path to exist
+func NewFileGate(path string) *FileGate {
+	return &FileGate{Path: path, Poll: 100 * time.Millisecond}
+}
+
+// Enter waits for the file to exist
+func (g *FileGate) Enter(ctx context.Context) (func(), error) {
+	for {
+		if _, err := os.Stat(g.Path); err == nil {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for %s: %w", g.Path, ctx.Err())
+		case <-time.After(g.Poll):
+		}
+	}
+}
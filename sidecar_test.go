@@ -0,0 +1,52 @@
+package dexter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSidecarWaitsForDrainedMarker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dexter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "drained")
+
+	// a marker left by a previous run must not open the gate
+	ioutil.WriteFile(path, nil, 0644)
+	marker, err := NewDrainedMarker(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sidecar := NewDexter(WithManualTrigger())
+	gate := NewFileGate(path)
+	gate.Poll = time.Millisecond
+	sidecar.AddGate(gate, time.Second)
+	proxy := NewTarget("proxy")
+	sidecar.Track(proxy)
+
+	sidecar.SimulateSignal(os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		sidecar.WaitAndKill()
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if proxy.State() != TargetRunning {
+		t.Fatal("sidecar shut down before the main process drained")
+	}
+
+	main := NewTarget("main")
+	main.TrackCloser(marker)
+	main.Kill()
+	<-done
+	if !sidecar.LastReport().Clean() {
+		t.Errorf("unexpected report %+v", sidecar.LastReport())
+	}
+}
@@ -0,0 +1,13 @@
+package dexter
+
+import "os"
+
+// shutdownSignals are the signals NewDexter listens for, Plan 9 only
+// has the interrupt note which can be caught
+var shutdownSignals = []os.Signal{os.Interrupt}
+
+// reloadSignals is empty, reloads can only be triggered with Reload
+var reloadSignals []os.Signal
+
+// terminateSignals are the signals platforms send to stop a process
+var terminateSignals = shutdownSignals
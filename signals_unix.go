@@ -0,0 +1,18 @@
+//go:build !windows && !plan9 && !js && !wasip1
+// +build !windows,!plan9,!js,!wasip1
+
+package dexter
+
+import (
+	"os"
+	"syscall"
+)
+
+// shutdownSignals are the signals NewDexter listens for
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// reloadSignals are the signals which trigger a reload once a Reloader is set
+var reloadSignals = []os.Signal{syscall.SIGHUP}
+
+// terminateSignals are the signals platforms send to stop a process
+var terminateSignals = []os.Signal{syscall.SIGTERM}
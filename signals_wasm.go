@@ -0,0 +1,16 @@
+//go:build js || wasip1
+// +build js wasip1
+
+package dexter
+
+import "os"
+
+// shutdownSignals is empty, there are no signals to listen for under
+// WebAssembly so shutdown can only be triggered programmatically
+var shutdownSignals []os.Signal
+
+// reloadSignals is empty, reloads can only be triggered with Reload
+var reloadSignals []os.Signal
+
+// terminateSignals is empty, like shutdownSignals
+var terminateSignals []os.Signal
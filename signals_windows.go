@@ -0,0 +1,16 @@
+package dexter
+
+import (
+	"os"
+	"syscall"
+)
+
+// shutdownSignals are the signals NewDexter listens for, os/signal
+// delivers SIGTERM on Windows for console close, logoff and shutdown events
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// reloadSignals is empty, reloads can only be triggered with Reload
+var reloadSignals []os.Signal
+
+// terminateSignals are the signals platforms send to stop a process
+var terminateSignals = []os.Signal{syscall.SIGTERM}
@@ -0,0 +1,82 @@
+package dexter
+
+import "time"
+
+// Snapshot is a point in time view of a Dexter and its targets.  It shares
+// nothing with the Dexter, so it can be kept, encoded as JSON or compared
+// in tests while targets keep changing.
+type Snapshot struct {
+	Taken    time.Time        `json:"taken"`
+	Serving  bool             `json:"serving"`
+	Stopping bool             `json:"stopping"`
+	Targets  []TargetSnapshot `json:"targets"`
+}
+
+// TargetSnapshot describes a target in kill order, with the deadline it is
+// held to, see PlannedTarget for DeadlineFrom
+type TargetSnapshot struct {
+	Name         string        `json:"name"`
+	Description  string        `json:"description,omitempty"`
+	Owner        string        `json:"owner,omitempty"`
+	Tags         []string      `json:"tags,omitempty"`
+	Phase        Phase         `json:"phase"`
+	State        TargetState   `json:"state"`
+	Stats        TargetStats   `json:"stats"`
+	Deadline     time.Duration `json:"deadline"`
+	Policy       OverrunPolicy `json:"policy"`
+	DeadlineFrom string        `json:"deadline_from"`
+	ClosePolicy  ClosePolicy   `json:"close_policy"`
+	Running      []string      `json:"running,omitempty"`
+}
+
+// Snapshot returns a view of every tracked target in kill order
+func (d *Dexter) Snapshot() *Snapshot {
+	snap := &Snapshot{
+		Taken:   time.Now(),
+		Serving: d.Serving(),
+		Targets: []TargetSnapshot{},
+	}
+	select {
+	case <-d.Stopping():
+		snap.Stopping = true
+	default:
+	}
+	for _, target := range d.killOrder() {
+		lim := d.limitFor(target)
+		snap.Targets = append(snap.Targets, TargetSnapshot{
+			Name:         target.name,
+			Description:  target.Description(),
+			Owner:        target.Owner(),
+			Tags:         target.Tags(),
+			Phase:        target.Phase(),
+			State:        target.State(),
+			Stats:        target.Stats(),
+			Deadline:     lim.deadline,
+			Policy:       lim.policy,
+			DeadlineFrom: lim.source,
+			ClosePolicy:  target.ClosePolicy(),
+			Running:      target.Running(),
+		})
+	}
+	return snap
+}
+
+// MarshalText encodes the phase by name
+func (p Phase) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// MarshalText encodes the state by name
+func (s TargetState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// MarshalText encodes the policy by name
+func (p OverrunPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// MarshalText encodes the policy by name
+func (p ClosePolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
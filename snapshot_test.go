@@ -0,0 +1,40 @@
+package dexter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.SetDefaultDeadline(time.Second, OverrunSkip)
+	flush := NewTarget("flush")
+	flush.SetDescription("flushes batches to the ledger")
+	flush.Tag("storage")
+	flush.TrackCloser(closerFunc(func() error { return nil }))
+	dex.TrackPhase(PhaseFlush, flush)
+
+	snap := dex.Snapshot()
+	flush.Tag("late")
+	if !snap.Serving || snap.Stopping || len(snap.Targets) != 1 {
+		t.Fatalf("unexpected snapshot %+v", snap)
+	}
+	got := snap.Targets[0]
+	if got.Description != "flushes batches to the ledger" || len(got.Tags) != 1 ||
+		got.Phase != PhaseFlush || got.State != TargetRunning || got.Stats.Closers != 1 ||
+		got.Deadline != time.Second || got.DeadlineFrom != "default" {
+		t.Errorf("unexpected target %+v", got)
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"phase":"flush"`, `"state":"running"`, `"policy":"skip"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s missing from %s", want, data)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package dexter
+
+// TargetState describes where a Target is in its shutdown
+type TargetState int
+
+const (
+	// TargetRunning targets have not been asked to shut down yet
+	TargetRunning TargetState = iota
+	// TargetKilling targets are closing their closers and channels
+	TargetKilling
+	// TargetDraining targets are waiting for their WaitGroup to reach zero
+	TargetDraining
+	// TargetStopped targets are completely shut down
+	TargetStopped
+)
+
+// watchBuffer is how many transitions a watcher may fall behind before
+// further transitions are dropped for it
+const watchBuffer = 8
+
+func (s TargetState) String() string {
+	switch s {
+	case TargetRunning:
+		return "running"
+	case TargetKilling:
+		return "killing"
+	case TargetDraining:
+		return "draining"
+	case TargetStopped:
+		return "stopped"
+	}
+	return "unknown"
+}
+
+// State returns the current state of the target
+func (t *Target) State() TargetState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state
+}
+
+// Watch returns a channel which receives every state transition of the
+// target.  The channel is closed after TargetStopped has been delivered,
+// if the target is already stopped it is returned closed with just that
+// state in it.  Transitions are never blocked on a slow watcher, they are
+// dropped for it instead.
+func (t *Target) Watch() <-chan TargetState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ch := make(chan TargetState, watchBuffer)
+	if t.state == TargetStopped {
+		ch <- TargetStopped
+		close(ch)
+		return ch
+	}
+	t.watchers = append(t.watchers, ch)
+	return ch
+}
+
+func (t *Target) setState(state TargetState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state = state
+	for _, ch := range t.watchers {
+		select {
+		case ch <- state:
+		default:
+		}
+		if state == TargetStopped {
+			close(ch)
+		}
+	}
+	if state == TargetStopped {
+		t.watchers = nil
+	}
+}
+
+// stopped marks the target, and every target it adopted, as stopped
+func (t *Target) stopped() {
+	t.mu.Lock()
+	adopted := t.adopted
+	t.mu.Unlock()
+	for _, other := range adopted {
+		other.stopped()
+	}
+	t.setState(TargetStopped)
+}
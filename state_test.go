@@ -0,0 +1,31 @@
+package dexter
+
+import "testing"
+
+func TestTargetWatch(t *testing.T) {
+	target := NewTarget("watched")
+	watch := target.Watch()
+	if target.State() != TargetRunning {
+		t.Fatalf("new target is %v", target.State())
+	}
+
+	target.Kill()
+
+	var got []TargetState
+	for state := range watch {
+		got = append(got, state)
+	}
+	want := []TargetState{TargetKilling, TargetDraining, TargetStopped}
+	if len(got) != len(want) {
+		t.Fatalf("got transitions %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got transitions %v, want %v", got, want)
+		}
+	}
+
+	if late := <-target.Watch(); late != TargetStopped {
+		t.Errorf("watching a stopped target returned %v", late)
+	}
+}
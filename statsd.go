@@ -0,0 +1,68 @@
+package dexter
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// StatsD is a Metrics sink which sends measurements to a StatsD
+// daemon over UDP.  When Tagged is set, tags are sent using the
+// DogStatsD extension (|#tag1,tag2), otherwise tag values are folded
+// into the metric name so plain StatsD servers can still tell
+// targets apart (target.duration.<name>).
+type StatsD struct {
+	conn   net.Conn
+	prefix string
+	Tagged bool
+}
+
+// NewStatsD dials a StatsD daemon at addr (host:port), every metric name is
+// prefixed with prefix followed by a dot, unless prefix is empty
+func NewStatsD(addr, prefix string) (*StatsD, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+	return &StatsD{conn: conn, prefix: prefix}, nil
+}
+
+// Timing sends value as a StatsD timer in milliseconds
+func (s *StatsD) Timing(name string, value time.Duration, tags ...string) {
+	ms := float64(value) / float64(time.Millisecond)
+	s.send(name, fmt.Sprintf("%g|ms", ms), tags)
+}
+
+// Count sends value as a StatsD counter
+func (s *StatsD) Count(name string, value int64, tags ...string) {
+	s.send(name, fmt.Sprintf("%d|c", value), tags)
+}
+
+// Close closes the underlying UDP socket
+func (s *StatsD) Close() error {
+	return s.conn.Close()
+}
+
+func (s *StatsD) send(name, value string, tags []string) {
+	name = s.prefix + name
+	if !s.Tagged {
+		for _, tag := range tags {
+			if i := strings.IndexByte(tag, ':'); i >= 0 {
+				tag = tag[i+1:]
+			}
+			name += "." + tag
+		}
+	}
+	line := name + ":" + value
+	if s.Tagged && len(tags) > 0 {
+		line += "|#" + strings.Join(tags, ",")
+	}
+	// metrics are best effort, a lost packet must never hold up shutdown
+	if _, err := s.conn.Write([]byte(line)); err != nil {
+		dlog.Printf("Failed to send metric %s: %v\n", name, err)
+	}
+}
@@ -0,0 +1,42 @@
+package dexter
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestStatsD(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	read := func() string {
+		buf := make([]byte, 512)
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf[:n])
+	}
+
+	sd, err := NewStatsD(conn.LocalAddr().String(), "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sd.Close()
+
+	sd.Timing("target.duration", 1500*time.Microsecond, "target:db")
+	if got := read(); got != "app.target.duration.db:1.5|ms" {
+		t.Errorf("unexpected plain statsd line %q", got)
+	}
+
+	sd.Tagged = true
+	sd.Count("closer.errors", 2, "target:db")
+	if got := read(); got != "app.closer.errors:2|c|#target:db" {
+		t.Errorf("unexpected dogstatsd line %q", got)
+	}
+}
@@ -0,0 +1,64 @@
+package dexter
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Streams helps server-sent-events and long-poll handlers end promptly when
+// shutdown starts, instead of holding the HTTP target open until the force
+// kill.  Streams are tracked by an InFlight so the target waits for them.
+//
+//	stop, done, ok := streams.Begin()
+//	if !ok {
+//		streams.EndLongPoll(w)
+//		return
+//	}
+//	defer done()
+//	for {
+//		select {
+//		case <-stop:
+//			streams.EndSSE(w)
+//			return
+//		case ev := <-events:
+//			...
+//		}
+//	}
+type Streams struct {
+	dexter   *Dexter
+	inflight *InFlight
+	retry    time.Duration
+}
+
+// NewStreams returns a stream helper, retry is the hint telling clients
+// when to reconnect
+func NewStreams(d *Dexter, inflight *InFlight, retry time.Duration) *Streams {
+	return &Streams{dexter: d, inflight: inflight, retry: retry}
+}
+
+// Begin registers a stream, stop is closed as soon as shutdown starts and
+// done must be called once the handler returns.  ok is false if the stream
+// should be refused because its target has already been killed.
+func (s *Streams) Begin() (stop <-chan struct{}, done func(), ok bool) {
+	done, ok = s.inflight.Begin()
+	return s.dexter.Stopping(), done, ok
+}
+
+// EndSSE sends the retry hint to an event stream and flushes it, the
+// handler should return right after
+func (s *Streams) EndSSE(w http.ResponseWriter) {
+	fmt.Fprintf(w, "retry: %d\n\n", s.retry/time.Millisecond)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// EndLongPoll answers a long-poll request with 503 Service Unavailable and a
+// Retry-After header
+func (s *Streams) EndLongPoll(w http.ResponseWriter) {
+	secs := int((s.retry + time.Second - 1) / time.Second)
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+	http.Error(w, "shutting down", http.StatusServiceUnavailable)
+}
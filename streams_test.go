@@ -0,0 +1,51 @@
+package dexter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStreamsEndOnShutdown(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	target := NewTarget("http")
+	dex.Track(target)
+	streams := NewStreams(dex, NewInFlight(target), 1500*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	ended := make(chan struct{})
+	stop, done, ok := streams.Begin()
+	if !ok {
+		t.Fatal("stream refused before shutdown")
+	}
+	go func() {
+		defer done()
+		defer close(ended)
+		<-stop
+		streams.EndSSE(rec)
+	}()
+
+	dex.SimulateSignal(os.Interrupt)
+	dex.WaitAndKill()
+
+	// the target waited for the stream to end
+	select {
+	case <-ended:
+	default:
+		t.Fatal("shutdown finished before the stream ended")
+	}
+	if rec.Body.String() != "retry: 1500\n\n" {
+		t.Errorf("unexpected retry hint %q", rec.Body.String())
+	}
+
+	if _, _, ok := streams.Begin(); ok {
+		t.Error("stream accepted after its target was killed")
+	}
+	rec = httptest.NewRecorder()
+	streams.EndLongPoll(rec)
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "2" {
+		t.Errorf("unexpected long-poll response %d %v", rec.Code, rec.Header())
+	}
+}
@@ -0,0 +1,100 @@
+package dexter
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultSummaryThreshold is how many closers a target may have before
+	// closing them is logged as periodic summaries instead of line by line
+	defaultSummaryThreshold = 100
+	// defaultSummaryCadence is how often summaries are logged
+	defaultSummaryCadence = time.Second
+)
+
+// SetLogSummary makes the target log closing its closers as a summary
+// every cadence, like "closed 4200/8000 closers, 3 errors", once it has
+// more than threshold of them, instead of a line for every failed closer.
+// By default targets with more than 100 closers are summarized every
+// second.
+func (t *Target) SetLogSummary(threshold int, cadence time.Duration) {
+	t.mu.Lock()
+	t.summaryThreshold = threshold
+	t.summaryCadence = cadence
+	t.mu.Unlock()
+}
+
+// closeLog logs the progress of closing a target's closers
+type closeLog struct {
+	target    *Target
+	total     int
+	count     int
+	errs      int
+	summarize bool
+	cadence   time.Duration
+	last      time.Time
+}
+
+func (t *Target) newCloseLog(total int) *closeLog {
+	t.mu.Lock()
+	threshold, cadence := t.summaryThreshold, t.summaryCadence
+	t.mu.Unlock()
+	if threshold <= 0 {
+		threshold = defaultSummaryThreshold
+	}
+	if cadence <= 0 {
+		cadence = defaultSummaryCadence
+	}
+	return &closeLog{
+		target:    t,
+		total:     total,
+		summarize: total > threshold,
+		cadence:   cadence,
+		last:      time.Now(),
+	}
+}
+
+// closed records that closer was closed, Close returned err
+func (l *closeLog) closed(closer interface{}, err error) {
+	l.count++
+	if err != nil {
+		l.errs++
+		if !l.summarize {
+			l.target.logf("Error closing %s in target %s: %v\n", resourceName(closer), l.target.name, err)
+		}
+	}
+	if l.summarize && time.Since(l.last) >= l.cadence {
+		l.log()
+	}
+}
+
+// shared records that closer was already closed by another target
+func (l *closeLog) shared(closer interface{}) {
+	l.count++
+	if !l.summarize {
+		l.target.logf("%s in target %s was already closed by another target\n", resourceName(closer), l.target.name)
+	}
+}
+
+// done logs the final summary
+func (l *closeLog) done() {
+	if l.summarize {
+		l.log()
+	}
+}
+
+func (l *closeLog) log() {
+	l.last = time.Now()
+	l.target.logf("Target %s: closed %s/%s closers, %d errors\n",
+		l.target.name, thousands(l.count), thousands(l.total), l.errs)
+}
+
+// thousands formats n with thousands separators
+func thousands(n int) string {
+	s := fmt.Sprint(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
@@ -0,0 +1,43 @@
+package dexter
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogSummary(t *testing.T) {
+	var buf bytes.Buffer
+	dlog.SetOutput(&buf)
+	defer dlog.SetOutput(os.Stdout)
+
+	target := NewTarget("conns")
+	target.SetLogSummary(10, time.Hour)
+	for i := 0; i < 4200; i++ {
+		var err error
+		if i%1400 == 0 {
+			err = errors.New("reset by peer")
+		}
+		target.TrackCloser(closerFunc(func() error { return err }))
+	}
+	target.kill()
+
+	out := buf.String()
+	if strings.Contains(out, "reset by peer") {
+		t.Error("closer errors logged one by one")
+	}
+	if !strings.Contains(out, "closed 4,200/4,200 closers, 3 errors") {
+		t.Errorf("summary missing from log:\n%s", out)
+	}
+}
+
+func TestThousands(t *testing.T) {
+	for n, want := range map[int]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567"} {
+		if got := thousands(n); got != want {
+			t.Errorf("thousands(%d) = %s, want %s", n, got, want)
+		}
+	}
+}
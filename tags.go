@@ -0,0 +1,69 @@
+package dexter
+
+// Tag attaches tags such as "network", "storage" or "optional" to the
+// target, see Dexter.KillTagged and Dexter.OrderTags
+func (t *Target) Tag(tags ...string) {
+	t.mu.Lock()
+	t.tags = append(t.tags, tags...)
+	t.mu.Unlock()
+}
+
+// Tags returns the tags attached to the target
+func (t *Target) Tags() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.tags...)
+}
+
+// HasTag reports whether tag is attached to the target
+func (t *Target) HasTag(tag string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, have := range t.tags {
+		if have == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// KillTagged kills the tracked targets tagged tag in kill order, without
+// shutting anything else down, e.g. to shed optional subsystems under
+// memory pressure.  The killed targets stay tracked, a later shutdown
+// passes over them.  The errors returned by their closers are returned.
+func (d *Dexter) KillTagged(tag string) []error {
+	var errs []error
+	for _, target := range d.killOrder() {
+		if !target.HasTag(tag) {
+			continue
+		}
+		target.logf("Killing target %s tagged %s\n", target.name, tag)
+		killed, _ := killTarget(target, d.limitFor(target), nil)
+		errs = append(errs, killed...)
+	}
+	return errs
+}
+
+// OrderTags orders the targets within each phase by their tags: targets
+// tagged tags[0] are killed first, then those tagged tags[1] and so on.
+// An empty tag stands for the targets carrying none of the tags, without
+// one they are killed last.  Targets of the same rank keep the order they
+// were tracked in.
+func (d *Dexter) OrderTags(tags ...string) {
+	d.mu.Lock()
+	d.tagOrder = append([]string(nil), tags...)
+	d.mu.Unlock()
+}
+
+// tagRank returns the position of target in order, d.mu must not be held
+func tagRank(order []string, target *Target) int {
+	untagged := len(order)
+	for i, tag := range order {
+		if tag == "" {
+			untagged = i
+		} else if target.HasTag(tag) {
+			return i
+		}
+	}
+	return untagged
+}
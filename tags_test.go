@@ -0,0 +1,48 @@
+package dexter
+
+import "testing"
+
+func TestKillTagged(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	cache, db := NewTarget("cache"), NewTarget("db")
+	cache.Tag("optional", "network")
+	dex.Track(cache)
+	dex.Track(db)
+
+	if errs := dex.KillTagged("optional"); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if cache.State() != TargetStopped {
+		t.Errorf("tagged target is %v", cache.State())
+	}
+	if db.State() != TargetRunning {
+		t.Errorf("untagged target is %v", db.State())
+	}
+}
+
+func TestOrderTags(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	names := []string{"db", "plain", "cache", "search"}
+	for i, name := range names {
+		target := NewTarget(name)
+		switch i {
+		case 0:
+			target.Tag("storage")
+		case 2, 3:
+			target.Tag("optional")
+		}
+		dex.Track(target)
+	}
+	dex.OrderTags("optional", "", "storage")
+
+	var got []string
+	for _, target := range dex.killOrder() {
+		got = append(got, target.Name())
+	}
+	want := []string{"cache", "search", "plain", "db"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("kill order %v, want %v", got, want)
+		}
+	}
+}
@@ -1,10 +1,15 @@
 package dexter
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
+	"log"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Target hold a wait group, channels and io.Closers
@@ -12,25 +17,104 @@ import (
 // stopped at once as in stage before moving on to next logical
 // group of targets
 type Target struct {
-	name      string
-	wg        sync.WaitGroup
-	channels  []interface{}
-	monitored []io.Closer
+	name         string
+	description  string
+	owner        string
+	tags         []string
+	wg           sync.WaitGroup
+	channels     []interface{}
+	sendsTo      []interface{}
+	channelNames map[interface{}]string
+	monitored    []io.Closer
+	shared       *sharedClosers
+	shares       map[interface{}]*sharedClose
+	unshared     bool
+	funcs        []trackedFunc
+	locks        []*trackedLock
+	adopted      []*Target
+	txs          map[*Rollbacker]struct{}
+	synced       []*syncedFile
+	quiescers    []Quiescer
+	producers    map[interface{}]*producers
+	phase        Phase
+	deadline     time.Duration
+	policy       OverrunPolicy
+	closePolicy  ClosePolicy
+	panicPolicy  PanicPolicy
+	funcsRun     bool
+	intakeClosed bool
+	intakeErrs   []error
+	fallback     func()
+	killIf       func() bool
+
+	summaryThreshold int
+	summaryCadence   time.Duration
+
+	mu         sync.Mutex
+	state      TargetState
+	watchers   []chan TargetState
+	pending    int
+	goroutines int
+	leases     int
+	named      map[string]int
+	goErrs     []error
+	killOnErr  bool
+	onRelease  []func(Release)
+	panics     []*PanicError
+	killed     chan struct{}
+	killDone   bool
+	killErr    *KillError
+	ctx        context.Context
+	logger     *log.Logger
+	logFields  string
+	// muted is non zero while a Dexter shows progress instead of the
+	// target's log lines
+	muted int32
+}
+
+// TargetStats counts what a target still has to tear down
+type TargetStats struct {
+	Funcs        int `json:"funcs"`
+	Transactions int `json:"transactions"`
+	Closers      int `json:"closers"`
+	Channels     int `json:"channels"`
+	// Pending is the current WaitGroup counter
+	Pending int `json:"pending"`
+	// Goroutines counts the goroutines started with Go still running
+	Goroutines int `json:"goroutines"`
+	// Leases counts the units of work begun with InFlight.Begin which
+	// aren't done yet, they are part of Pending
+	Leases int `json:"leases"`
 }
 
 // NewTarget builds a new target to be tracked and killed by dexter
 func NewTarget(name string) *Target {
 	target := &Target{
 		name:      name,
+		phase:     PhaseWorkers,
 		monitored: []io.Closer{},
 	}
 
 	return target
 }
 
+// Name returns the name the target was created with
+func (t *Target) Name() string {
+	return t.name
+}
+
 // TrackCloser keeps list of io.Closers to stop when we receive the shutdown signal
+// A pointer closer tracked by several targets of the same Dexter is only
+// closed by the first one killed.
 func (t *Target) TrackCloser(closer io.Closer) {
+	t.mu.Lock()
 	t.monitored = append(t.monitored, closer)
+	if t.shared != nil && !t.unshared {
+		if s := t.shared.share(closer); s != nil {
+			t.shares[unwrap(closer)] = s
+		}
+	}
+	t.mu.Unlock()
 }
 
 // TrackChannel keeps a list of channels to be closed upon receiving
@@ -40,35 +124,360 @@ func (t *Target) TrackCloser(closer io.Closer) {
 // If passed value is NOT of type chan - an error will be returned.
 func (t *Target) TrackChannel(channel interface{}) error {
 	if reflect.TypeOf(channel).Kind() == reflect.Chan {
+		t.mu.Lock()
 		t.channels = append(t.channels, channel)
+		t.mu.Unlock()
 		return nil
 	}
 	return errors.New("channel is not of type chan")
 }
 
+// WaitGroupError is the panic value used when a target's Add or Done is
+// misused, unlike the plain sync.WaitGroup panic it names the target
+type WaitGroupError struct {
+	Target  string
+	Delta   int
+	Pending int
+	Reason  string
+}
+
+func (e *WaitGroupError) Error() string {
+	return fmt.Sprintf("dexter: target %s: %s (delta %d, %d pending)",
+		e.Target, e.Reason, e.Delta, e.Pending)
+}
+
+// TrackCancel wires a context driven component into the shutdown, cancel is
+// called when the target is killed, before anything else is closed and
+// before the target waits for its WaitGroup
+func (t *Target) TrackCancel(cancel context.CancelFunc) {
+	t.trackFunc(func() { cancel() })
+}
+
+// Adopt merges other into the target, other's funcs, closers, channels
+// and locks are released whenever the target is killed, right after the
+// target's own, and Wait also waits for other's WaitGroup.  Resources
+// tracked on other after it was adopted are included as well.  Adopting a
+// target which already adopts t, directly or not, is rejected with an
+// *AdoptionCycleError.
+func (t *Target) Adopt(other *Target) error {
+	if other == t {
+		return nil
+	}
+	adoptions.Lock()
+	defer adoptions.Unlock()
+	if path := other.adoptionPath(t); path != nil {
+		return &AdoptionCycleError{Cycle: append([]string{t.name}, path...)}
+	}
+	t.mu.Lock()
+	t.adopted = append(t.adopted, other)
+	shared := t.shared
+	if t.unshared {
+		shared = nil
+	}
+	t.mu.Unlock()
+	if shared != nil {
+		other.join(shared)
+	}
+	return nil
+}
+
+// adoptions serializes Adopt, so two adoptions can't form a cycle together
+var adoptions sync.Mutex
+
+// AdoptionCycleError is returned by Target.Adopt when the adoption would
+// make a target adopt itself, Cycle lists the target names along the
+// cycle, starting and ending with the same name
+type AdoptionCycleError struct {
+	Cycle []string
+}
+
+func (e *AdoptionCycleError) Error() string {
+	return "dexter: adoption cycle: " + strings.Join(e.Cycle, " -> ")
+}
+
+// adoptionPath returns the names along a chain of adoptions leading from t
+// to to, nil if there is none
+func (t *Target) adoptionPath(to *Target) []string {
+	if t == to {
+		return []string{to.name}
+	}
+	t.mu.Lock()
+	adopted := t.adopted
+	t.mu.Unlock()
+	for _, other := range adopted {
+		if path := other.adoptionPath(to); path != nil {
+			return append([]string{t.name}, path...)
+		}
+	}
+	return nil
+}
+
+// KillIf makes shutdown skip the target unless pred returns true, e.g. for
+// stages behind a feature flag or resources which may never have been
+// initialized.  pred is called when the target's turn comes.  Kill and
+// KillTagged are not affected.
+func (t *Target) KillIf(pred func() bool) {
+	t.mu.Lock()
+	t.killIf = pred
+	t.mu.Unlock()
+}
+
+// wanted reports whether shutdown should kill the target
+func (t *Target) wanted() bool {
+	t.mu.Lock()
+	pred := t.killIf
+	t.mu.Unlock()
+	return pred == nil || pred()
+}
+
+// trackFunc registers fn to be run when the target is killed, funcs run
+// before any closer or channel is closed
+func (t *Target) trackFunc(fn func()) {
+	t.trackFuncNamed("", fn)
+}
+
+// trackedFunc is a func run when the target is killed
+type trackedFunc struct {
+	name string
+	fn   func()
+}
+
+// trackFuncNamed is trackFunc for a func with a name
+func (t *Target) trackFuncNamed(name string, fn func()) {
+	t.mu.Lock()
+	t.funcs = append(t.funcs, trackedFunc{name: name, fn: fn})
+	t.mu.Unlock()
+}
+
 // Add is a really thin wrapper around sync.WorkGroup.Add
+// It panics with a *WaitGroupError if the counter would go negative, or if
+// work is added after the target was killed and had already drained
 func (t *Target) Add(delta int) {
+	t.mu.Lock()
+	var reason string
+	switch {
+	case t.pending+delta < 0 && t.pending == 0:
+		reason = "Done called without a matching Add"
+	case t.pending+delta < 0:
+		reason = "negative WaitGroup counter"
+	case delta > 0 && t.pending == 0 && t.state != TargetRunning:
+		reason = "Add called after the target was killed"
+	}
+	if reason != "" {
+		err := &WaitGroupError{Target: t.name, Delta: delta, Pending: t.pending, Reason: reason}
+		t.mu.Unlock()
+		panic(err)
+	}
+	t.pending += delta
+	t.mu.Unlock()
 	t.wg.Add(delta)
 }
 
+// tryAdd leases one unit of work unless the target has been killed,
+// endLease ends it
+func (t *Target) tryAdd() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.state != TargetRunning {
+		return false
+	}
+	t.pending++
+	t.leases++
+	t.wg.Add(1)
+	return true
+}
+
+// endLease ends a unit of work leased with tryAdd
+func (t *Target) endLease() {
+	t.mu.Lock()
+	t.leases--
+	t.mu.Unlock()
+	t.Done()
+}
+
 // Done is a really thin wrapper around sync.WorkGroup.Done
 func (t *Target) Done() {
-	t.wg.Done()
+	t.Add(-1)
+}
+
+// Stats returns counts of the resources tracked by the target, including
+// adopted targets
+func (t *Target) Stats() TargetStats {
+	t.mu.Lock()
+	stats := TargetStats{
+		Funcs:        len(t.funcs),
+		Transactions: len(t.txs),
+		Closers:      len(t.monitored),
+		Channels:     len(t.channels),
+		Pending:      t.pending,
+		Goroutines:   t.goroutines,
+		Leases:       t.leases,
+	}
+	adopted := t.adopted
+	t.mu.Unlock()
+
+	for _, other := range adopted {
+		o := other.Stats()
+		stats.Funcs += o.Funcs
+		stats.Transactions += o.Transactions
+		stats.Closers += o.Closers
+		stats.Channels += o.Channels
+		stats.Pending += o.Pending
+		stats.Goroutines += o.Goroutines
+		stats.Leases += o.Leases
+	}
+	return stats
 }
 
 // Wait is a really thin wrapper around sync.WorGroup.Wait
+// It also waits for adopted targets
 func (t *Target) Wait() {
 	t.wg.Wait()
+	t.mu.Lock()
+	adopted := t.adopted
+	t.mu.Unlock()
+	for _, other := range adopted {
+		other.Wait()
+	}
+}
+
+// Kill closes everything the target tracks and waits for it to drain,
+// outside of any Dexter's kill sequence.  Killing a target more than once
+// is a no-op.
+func (t *Target) Kill() {
+	killTarget(t, limit{}, nil)
+	t.repanic()
+}
+
+// kill closes everything the target tracks and returns the errors
+// reported by its io.Closers, only the first call does anything
+func (t *Target) kill() []error {
+	return t.killContext(context.Background())
+}
+
+// killContext is kill with ctx passed to closers implementing
+// ContextCloser, it carries the target's deadline
+func (t *Target) killContext(ctx context.Context) []error {
+	t.mu.Lock()
+	if t.state != TargetRunning {
+		t.mu.Unlock()
+		return nil
+	}
+	monitored, adopted := t.monitored, t.adopted
+	intakeClosed, errs := t.intakeClosed, t.intakeErrs
+	t.mu.Unlock()
+	t.setState(TargetKilling)
+
+	t.logf("Killing target %s\n", t.label())
+	closeIntake := func() {
+		if !intakeClosed {
+			errs = append(errs, t.closeIntake(ctx)...)
+		}
+	}
+	if t.ClosePolicy() == WaitThenClose {
+		closeIntake()
+		t.logf("Waiting for target %s to drain before closing its closers\n", t.label())
+		t.wg.Wait()
+		errs = append(errs, t.rollback()...)
+		errs = append(errs, t.closeClosers(ctx, monitored)...)
+	} else {
+		errs = append(errs, t.runFuncs(ctx)...)
+		errs = append(errs, t.rollback()...)
+		errs = append(errs, t.closeClosers(ctx, monitored)...)
+		if !intakeClosed {
+			errs = append(errs, t.closeChannels()...)
+		}
+	}
+
+	for _, other := range adopted {
+		errs = append(errs, other.killContext(ctx)...)
+	}
+	t.setState(TargetDraining)
+	return errs
 }
 
-func (t *Target) kill() {
-	dlog.Printf("Killing target %s\n", t.name)
-	for _, val := range t.monitored {
-		val.Close()
+// closeClosers closes monitored, closers shared with other targets are
+// only closed by the first one
+func (t *Target) closeClosers(ctx context.Context, monitored []io.Closer) (errs []error) {
+	t.mu.Lock()
+	shares, unref := t.shares, !t.unshared
+	t.unshared = true
+	t.mu.Unlock()
+	progress := t.newCloseLog(len(monitored))
+	yield := newYielder()
+	for _, val := range monitored {
+		yield.step()
+		first := true
+		err := t.guard(ctx, resourceName(val), func() (err error) {
+			first, err = closeShared(ctx, val, shares, unref)
+			return err
+		})
+		if !first {
+			progress.shared(val)
+			continue
+		}
+		progress.closed(val, err)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		t.released(ResourceCloser, resourceName(val), unwrap(val), err)
+	}
+	progress.done()
+	return errs
+}
+
+// runFuncs runs the target's funcs and returns their panics, only the
+// first call does anything
+func (t *Target) runFuncs(ctx context.Context) (errs []error) {
+	t.mu.Lock()
+	funcs := t.funcs
+	done := t.funcsRun
+	t.funcsRun = true
+	t.mu.Unlock()
+	if done {
+		return nil
 	}
+	yield := newYielder()
+	for _, f := range funcs {
+		yield.step()
+		name := f.name
+		if name == "" {
+			name = "func"
+		}
+		err := t.guard(ctx, name, func() error {
+			f.fn()
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+		t.released(ResourceFunc, f.name, nil, err)
+	}
+	return errs
+}
+
+// closeIntake runs the funcs and closes the channels, whatever feeds the
+// target work, without touching its closers
+func (t *Target) closeIntake(ctx context.Context) []error {
+	t.mu.Lock()
+	t.intakeClosed = true
+	t.mu.Unlock()
+	errs := t.runFuncs(ctx)
+	return append(errs, t.closeChannels()...)
+}
 
-	dlog.Printf("Closing %d channels\n", len(t.channels))
-	for _, channel := range t.channels {
-		reflect.ValueOf(channel).Close()
+// closeChannels closes the target's channels
+func (t *Target) closeChannels() (errs []error) {
+	t.mu.Lock()
+	channels := t.channels
+	t.mu.Unlock()
+	t.logf("Closing %d channels\n", len(channels))
+	yield := newYielder()
+	for _, channel := range channels {
+		yield.step()
+		if err := t.closeChannel(channel); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errs
 }
@@ -0,0 +1,144 @@
+package dexter
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTargetStats(t *testing.T) {
+	target := NewTarget("stats")
+	target.TrackCloser(dcloser{})
+	target.TrackChannel(make(chan int))
+	target.TrackChannel(make(chan string))
+	target.Add(3)
+	target.Done()
+	requests := NewInFlight(target)
+	done, _ := requests.Begin()
+	requests.Begin()
+	done()
+
+	want := TargetStats{Closers: 1, Channels: 2, Pending: 3, Leases: 1}
+	if got := target.Stats(); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestWaitGroupMisuse(t *testing.T) {
+	expect := func(reason string, fn func()) {
+		t.Helper()
+		defer func() {
+			err, ok := recover().(*WaitGroupError)
+			if !ok {
+				t.Errorf("expected a *WaitGroupError for %q", reason)
+				return
+			}
+			if err.Target != "misused" || err.Reason != reason {
+				t.Errorf("got %v, expected %q", err, reason)
+			}
+		}()
+		fn()
+	}
+
+	target := NewTarget("misused")
+	expect("Done called without a matching Add", target.Done)
+
+	target.Add(1)
+	expect("negative WaitGroup counter", func() { target.Add(-2) })
+
+	target.Done()
+	target.Kill()
+	expect("Add called after the target was killed", func() { target.Add(1) })
+}
+
+func TestTrackCancel(t *testing.T) {
+	target := NewTarget("ctx")
+	ctx, cancel := context.WithCancel(context.Background())
+	target.TrackCancel(cancel)
+	target.TrackCloser(closerFunc(func() error {
+		if ctx.Err() == nil {
+			t.Error("closer ran before the context was cancelled")
+		}
+		return nil
+	}))
+
+	target.Add(1)
+	go func() {
+		defer target.Done()
+		<-ctx.Done()
+	}()
+
+	target.Kill()
+	if target.Stats().Funcs != 1 {
+		t.Error("cancel func not counted in stats")
+	}
+}
+
+func TestTargetAdopt(t *testing.T) {
+	parent := NewTarget("parent")
+	child := NewTarget("child")
+	parent.Adopt(child)
+
+	ch := make(chan int)
+	child.TrackChannel(ch)
+	child.Add(1)
+	go func() {
+		defer child.Done()
+		for range ch {
+		}
+	}()
+
+	if stats := parent.Stats(); stats.Channels != 1 || stats.Pending != 1 {
+		t.Errorf("adopted resources missing from stats: %+v", stats)
+	}
+
+	parent.Kill()
+	if child.State() != TargetStopped {
+		t.Errorf("adopted target is %v after kill", child.State())
+	}
+}
+
+func TestTargetAdoptCycle(t *testing.T) {
+	a, b, c := NewTarget("a"), NewTarget("b"), NewTarget("c")
+	if err := a.Adopt(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Adopt(c); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Adopt(a)
+	cycle, ok := err.(*AdoptionCycleError)
+	if !ok {
+		t.Fatalf("got %v", err)
+	}
+	if got := strings.Join(cycle.Cycle, " "); got != "c a b c" {
+		t.Errorf("cycle %q", got)
+	}
+	if stats := a.Stats(); stats.Funcs != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	a.Kill()
+	if c.State() != TargetStopped {
+		t.Errorf("adopted target is %v after kill", c.State())
+	}
+}
+
+func TestKillIf(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	var initialized bool
+	inert, live := NewTarget("inert"), NewTarget("live")
+	inert.KillIf(func() bool { return initialized })
+	live.KillIf(func() bool { return true })
+	dex.Track(inert)
+	dex.Track(live)
+
+	dex.Shutdown("test")
+	dex.WaitAndKill()
+
+	if inert.State() != TargetRunning || live.State() != TargetStopped {
+		t.Errorf("inert is %v, live is %v", inert.State(), live.State())
+	}
+	if report := dex.LastReport(); !report.Targets[0].Skipped || report.Targets[1].Skipped {
+		t.Errorf("unexpected report %+v", report.Targets)
+	}
+}
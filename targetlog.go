@@ -0,0 +1,55 @@
+package dexter
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// SetLogger routes the target's shutdown logs to logger instead of
+// dexter's own, e.g. to the owning team's log stream in a binary shared by
+// several teams.  A nil logger routes them back.
+func (t *Target) SetLogger(logger *log.Logger) {
+	t.mu.Lock()
+	t.logger = logger
+	t.mu.Unlock()
+}
+
+// SetLogFields appends fields, given as alternating keys and values, to
+// every shutdown log line of the target, such as
+// SetLogFields("service", "billing", "component", "ledger")
+func (t *Target) SetLogFields(keyvals ...string) {
+	var fields []string
+	for i := 0; i < len(keyvals); i += 2 {
+		value := ""
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		if strings.ContainsAny(value, " \t\"=") {
+			value = fmt.Sprintf("%q", value)
+		}
+		fields = append(fields, keyvals[i]+"="+value)
+	}
+	t.mu.Lock()
+	t.logFields = strings.Join(fields, " ")
+	t.mu.Unlock()
+}
+
+// logf logs a line about the target to its logger with its fields
+func (t *Target) logf(format string, args ...interface{}) {
+	if atomic.LoadInt32(&t.muted) > 0 {
+		return
+	}
+	t.mu.Lock()
+	logger, fields := t.logger, t.logFields
+	t.mu.Unlock()
+	if logger == nil {
+		logger = dlog
+	}
+	msg := strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")
+	if fields != "" {
+		msg += " " + fields
+	}
+	logger.Println(msg)
+}
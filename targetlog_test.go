@@ -0,0 +1,26 @@
+package dexter
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestTargetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	target := NewTarget("ledger")
+	target.SetLogger(log.New(&buf, "", 0))
+	target.SetLogFields("service", "billing", "team", "payments core")
+	target.TrackCloser(closerFunc(func() error { return errors.New("boom") }))
+
+	target.Kill()
+	logs := buf.String()
+	if !strings.Contains(logs, "Killing target ledger service=billing team=\"payments core\"\n") {
+		t.Errorf("logs missing the target's fields:\n%s", logs)
+	}
+	if !strings.Contains(logs, "Error closing dexter.closerFunc in target ledger: boom service=billing") {
+		t.Errorf("closer error not routed to the target's logger:\n%s", logs)
+	}
+}
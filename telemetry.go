@@ -0,0 +1,27 @@
+package dexter
+
+// TrackFinalPush tracks a target called name in PhaseTelemetry which calls
+// push once every business target has been killed, e.g. to flush an OTLP
+// exporter or push to a Prometheus pushgateway, so the last interval of
+// the process's life isn't lost.  push receives the shutdown report so far,
+// covering every target killed before it.
+func (d *Dexter) TrackFinalPush(name string, push func(report *Report) error) *Target {
+	target := NewTarget(name)
+	target.TrackCloser(finalPush{d: d, push: push})
+	d.TrackPhase(PhaseTelemetry, target)
+	return target
+}
+
+type finalPush struct {
+	d    *Dexter
+	push func(report *Report) error
+}
+
+func (f finalPush) Close() error {
+	report := f.d.reportSoFar()
+	if report == nil {
+		// killed outside of a shutdown, there is nothing to report
+		report = &Report{}
+	}
+	return f.push(report)
+}
@@ -0,0 +1,27 @@
+package dexter
+
+import (
+	"os"
+	"testing"
+)
+
+func TestTrackFinalPush(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	var pushed *Report
+	dex.TrackFinalPush("metrics", func(report *Report) error {
+		pushed = report
+		return nil
+	})
+	dex.Track(NewTarget("workers"))
+	dex.TrackPhase(PhaseStorage, NewTarget("db"))
+
+	dex.SimulateSignal(os.Interrupt)
+	dex.WaitAndKill()
+
+	if pushed == nil || len(pushed.Targets) != 2 || pushed.Targets[1].Name != "db" {
+		t.Fatalf("push did not see the business targets: %+v", pushed)
+	}
+	if pushed.Signal != os.Interrupt {
+		t.Errorf("pushed report has signal %v", pushed.Signal)
+	}
+}
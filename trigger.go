@@ -0,0 +1,86 @@
+package dexter
+
+import (
+	"context"
+	"time"
+)
+
+// Trigger is a source of shutdowns, such as a context, an error channel or
+// a watchdog.  Triggers added with AddTrigger share the reason, kill plan
+// and report plumbing of signals and Shutdown.
+type Trigger interface {
+	// Watch blocks until shutdown should start and returns its reason.
+	// ctx is canceled once shutdown started for another reason, Watch
+	// then returns ok false.
+	Watch(ctx context.Context) (reason string, ok bool)
+}
+
+// TriggerFunc adapts a func to a Trigger
+type TriggerFunc func(ctx context.Context) (reason string, ok bool)
+
+// Watch calls f
+func (f TriggerFunc) Watch(ctx context.Context) (string, bool) {
+	return f(ctx)
+}
+
+// AddTrigger starts the graceful shutdown with the reason returned by
+// trigger, see Shutdown.  Watching stops when shutdown starts for any
+// other reason, a Restart ends the triggers added before it.
+func (d *Dexter) AddTrigger(trigger Trigger) {
+	d.addTrigger("trigger", trigger)
+}
+
+// addTrigger is AddTrigger with the goroutine's pprof role
+func (d *Dexter) addTrigger(role string, trigger Trigger) {
+	ctx := d.SignalContext()
+	go labelled("", role, func() {
+		if reason, ok := trigger.Watch(ctx); ok {
+			d.Shutdown(reason)
+		}
+	})
+}
+
+// ContextTrigger starts shutdown when ctx is done, the context's error is
+// the reason
+func ContextTrigger(ctx context.Context) Trigger {
+	return TriggerFunc(func(stop context.Context) (string, bool) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err().Error(), true
+		case <-stop.Done():
+			return "", false
+		}
+	})
+}
+
+// ErrorTrigger starts shutdown when errs delivers an error, e.g. from a
+// server's Serve, its message is the reason.  A closed errs or a nil error
+// starts nothing.
+func ErrorTrigger(errs <-chan error) Trigger {
+	return TriggerFunc(func(stop context.Context) (string, bool) {
+		select {
+		case err, ok := <-errs:
+			if !ok || err == nil {
+				return "", false
+			}
+			dlog.Printf("Shutting down on error: %v\n", err)
+			return err.Error(), true
+		case <-stop.Done():
+			return "", false
+		}
+	})
+}
+
+// TimerTrigger starts shutdown with reason once after elapsed
+func TimerTrigger(after time.Duration, reason string) Trigger {
+	return TriggerFunc(func(stop context.Context) (string, bool) {
+		timer := time.NewTimer(after)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return reason, true
+		case <-stop.Done():
+			return "", false
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package dexter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestErrorTrigger(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	errs := make(chan error, 1)
+	dex.AddTrigger(ErrorTrigger(errs))
+	errs <- errors.New("listener died")
+
+	dex.WaitAndKill()
+	if reason := dex.LastReport().Reason; reason != "listener died" {
+		t.Errorf("shut down for %q", reason)
+	}
+}
+
+func TestTriggerStopsOnShutdown(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	stopped := make(chan struct{})
+	dex.AddTrigger(TriggerFunc(func(ctx context.Context) (string, bool) {
+		<-ctx.Done()
+		close(stopped)
+		return "", false
+	}))
+	dex.AddTrigger(TimerTrigger(10*time.Millisecond, "deadline"))
+
+	dex.WaitAndKill()
+	if reason := dex.LastReport().Reason; reason != "deadline" {
+		t.Errorf("shut down for %q", reason)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("trigger kept watching after shutdown started")
+	}
+}
@@ -0,0 +1,52 @@
+package dexter
+
+// Rollbacker is implemented by database transactions, such as *sql.Tx
+type Rollbacker interface {
+	Rollback() error
+}
+
+// TrackTx rolls tx back when the target is killed, rather than leaving it
+// to time out server side.  Call untrack once tx has been committed or
+// rolled back by the application.  Transactions are rolled back after the
+// target's funcs ran and before its closers are closed, so the connections
+// they need are still open.
+func (t *Target) TrackTx(tx Rollbacker) (untrack func()) {
+	key := &tx
+	t.mu.Lock()
+	if t.txs == nil {
+		t.txs = map[*Rollbacker]struct{}{}
+	}
+	t.txs[key] = struct{}{}
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		delete(t.txs, key)
+		t.mu.Unlock()
+	}
+}
+
+// rollback rolls back every transaction still tracked by the target
+func (t *Target) rollback() []error {
+	t.mu.Lock()
+	txs := make([]Rollbacker, 0, len(t.txs))
+	for key := range t.txs {
+		txs = append(txs, *key)
+	}
+	t.txs = nil
+	t.mu.Unlock()
+
+	if len(txs) > 0 {
+		t.logf("Rolling back %d transactions\n", len(txs))
+	}
+	var errs []error
+	for _, tx := range txs {
+		err := tx.Rollback()
+		if err != nil {
+			t.logf("Error rolling back %T in target %s: %v\n", tx, t.name, err)
+			errs = append(errs, err)
+		}
+		t.released(ResourceTx, resourceName(tx), tx, err)
+	}
+	return errs
+}
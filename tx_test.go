@@ -0,0 +1,28 @@
+package dexter
+
+import "testing"
+
+type fakeTx struct {
+	rolledBack bool
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.rolledBack = true
+	return nil
+}
+
+func TestTrackTx(t *testing.T) {
+	target := NewTarget("db")
+	open, committed := &fakeTx{}, &fakeTx{}
+	target.TrackTx(open)
+	untrack := target.TrackTx(committed)
+	untrack()
+
+	if n := target.Stats().Transactions; n != 1 {
+		t.Errorf("stats count %d transactions", n)
+	}
+	target.Kill()
+	if !open.rolledBack || committed.rolledBack {
+		t.Errorf("open rolled back: %v, committed rolled back: %v", open.rolledBack, committed.rolledBack)
+	}
+}
@@ -0,0 +1,45 @@
+package dexter
+
+import (
+	"context"
+	"time"
+)
+
+// WithMinUptime guards against shutdowns signalled within d of NewDexter,
+// typical of crash looping restarts and misfiring probes.  Such a shutdown
+// is held until the process is up for d, or until the signal is sent
+// again, and its report is marked EarlyTermination.  Shutdown, Close and
+// parent Dexters are not held.
+func WithMinUptime(d time.Duration) Option {
+	return func(dex *Dexter) {
+		dex.minUptime = d
+	}
+}
+
+// holdEarly holds a signalled shutdown until the minimum uptime is reached
+// or the signal is repeated, ok is false when d was closed or ctx done
+// meanwhile
+func (d *Dexter) holdEarly(ctx context.Context, trig trigger) (held trigger, ok bool) {
+	uptime := time.Since(d.born)
+	if trig.signal == nil || uptime >= d.minUptime {
+		return trig, true
+	}
+	trig.early = true
+	wait := d.minUptime - uptime
+	d.logf("Received %v signal %v after start, shutting down in %v unless it is sent again\n",
+		trig.signal, uptime.Round(time.Millisecond), wait.Round(time.Millisecond))
+	d.metrics.Count("shutdown.early", 1)
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case sig := <-d.waiter:
+		d.logf("Received %v signal again, shutting down\n", sig)
+	case <-d.closed:
+		return trig, false
+	case <-ctx.Done():
+		return trig, false
+	}
+	return trig, true
+}
@@ -0,0 +1,53 @@
+package dexter
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMinUptimeHolds(t *testing.T) {
+	dex := NewDexter(WithManualTrigger(), WithMinUptime(50*time.Millisecond))
+	dex.SimulateSignal(os.Interrupt)
+	dex.WaitAndKill()
+
+	report := dex.LastReport()
+	if !report.EarlyTermination {
+		t.Error("early termination was not reported")
+	}
+	if report.Uptime < 50*time.Millisecond {
+		t.Errorf("shutdown started after %v", report.Uptime)
+	}
+}
+
+func TestMinUptimeRepeatedSignal(t *testing.T) {
+	dex := NewDexter(WithManualTrigger(), WithMinUptime(time.Minute))
+	dex.SimulateSignal(os.Interrupt)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		dex.SimulateSignal(os.Interrupt)
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		dex.WaitAndKill()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("second signal did not start the shutdown")
+	}
+	if !dex.LastReport().EarlyTermination {
+		t.Error("early termination was not reported")
+	}
+}
+
+func TestMinUptimeIgnoresShutdown(t *testing.T) {
+	dex := NewDexter(WithManualTrigger(), WithMinUptime(time.Minute))
+	dex.Shutdown("deploy")
+	dex.WaitAndKill()
+	if dex.LastReport().EarlyTermination {
+		t.Error("Shutdown was held")
+	}
+}
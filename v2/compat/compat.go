@@ -0,0 +1,186 @@
+// Package compat implements dexter v1's API on top of v2, so v1 call
+// sites keep working during the migration by changing their import path
+// from github.com/ceocoder/dexter to github.com/ceocoder/dexter/v2/compat.
+//
+// Only the core of v1 is covered: NewDexter, Track, WaitAndKill, NewTarget,
+// TrackCloser, TrackChannel and the WaitGroup methods.  Target.V2 returns
+// the underlying v2 target, so new resources can be tracked with the v2 API
+// while old ones are still wired through the shim.
+package compat
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	dexter "github.com/ceocoder/dexter/v2"
+)
+
+var dlog = log.New(os.Stdout, "[Dexter] ", log.Ldate|log.Ltime)
+
+// Dexter is v1's Dexter: targets are killed one after the other, in the
+// order they were tracked
+type Dexter struct {
+	dex             *dexter.Dexter
+	forceKillWindow time.Duration
+	exitFunc        func(int)
+}
+
+// NewDexter returns a Dexter listening for SIGINT and SIGTERM
+func NewDexter() *Dexter {
+	return &Dexter{
+		dex:             dexter.New(),
+		forceKillWindow: 5 * time.Second,
+		exitFunc:        os.Exit,
+	}
+}
+
+// SetForceKillInterval sets how long shutdown may take before the process
+// exits with a non-zero return code
+func (d *Dexter) SetForceKillInterval(interval time.Duration) {
+	d.forceKillWindow = interval
+}
+
+// Track adds target to the kill list, in a stage of its own
+func (d *Dexter) Track(target *Target) {
+	d.dex.Stage(target.target)
+}
+
+// WaitAndKill waits for SIGINT or SIGTERM and kills every target, exiting
+// the process if that takes longer than the force kill interval
+func (d *Dexter) WaitAndKill() {
+	dlog.Println("Started Dexter - waiting for SIGINT or SIGTERM")
+	sig, _ := d.dex.Wait(context.Background())
+	dlog.Printf("Received %v signal, shutting down\n", sig)
+	d.kill()
+}
+
+func (d *Dexter) kill() {
+	ctx, cancel := context.WithTimeout(context.Background(), d.forceKillWindow)
+	defer cancel()
+	if err := d.dex.Shutdown(ctx); err != nil {
+		dlog.Println(err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			dlog.Println("Timeout! - force exiting")
+			d.exitFunc(1)
+			return
+		}
+	}
+	dlog.Println("Killed all targets returning control")
+}
+
+// Target is v1's Target: on kill its closers are closed, then its
+// channels, then it waits for its WaitGroup
+type Target struct {
+	target *dexter.Target
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	closers  []io.Closer
+	channels []interface{}
+}
+
+// NewTarget returns a target called name
+func NewTarget(name string) *Target {
+	t := &Target{target: dexter.NewTarget(name)}
+	t.target.Track(dexter.ResourceFunc(t.kill))
+	return t
+}
+
+// V2 returns the v2 target the shim wraps
+func (t *Target) V2() *dexter.Target {
+	return t.target
+}
+
+// TrackCloser keeps a list of io.Closers closed on shutdown
+func (t *Target) TrackCloser(closer io.Closer) {
+	t.mu.Lock()
+	t.closers = append(t.closers, closer)
+	t.mu.Unlock()
+}
+
+// TrackChannel keeps a list of channels closed on shutdown, an error is
+// returned if channel isn't one
+func (t *Target) TrackChannel(channel interface{}) error {
+	if reflect.TypeOf(channel).Kind() != reflect.Chan {
+		return errors.New("channel is not of type chan")
+	}
+	t.mu.Lock()
+	t.channels = append(t.channels, channel)
+	t.mu.Unlock()
+	return nil
+}
+
+// Add is sync.WaitGroup.Add
+func (t *Target) Add(delta int) {
+	t.wg.Add(delta)
+}
+
+// Done is sync.WaitGroup.Done
+func (t *Target) Done() {
+	t.wg.Done()
+}
+
+// Wait is sync.WaitGroup.Wait
+func (t *Target) Wait() {
+	t.wg.Wait()
+}
+
+// Kill kills the target outside of a Dexter
+func (t *Target) Kill() {
+	if err := t.target.Kill(context.Background()); err != nil {
+		dlog.Println(err)
+	}
+}
+
+// kill is the v2 resource doing v1's kill sequence
+func (t *Target) kill(ctx context.Context) error {
+	t.mu.Lock()
+	closers, channels := t.closers, t.channels
+	t.mu.Unlock()
+
+	var errs []error
+	for _, closer := range closers {
+		if err := closeContext(ctx, closer); err != nil {
+			errs = append(errs, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	for _, channel := range channels {
+		reflect.ValueOf(channel).Close()
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		errs = append(errs, ctx.Err())
+	}
+	return errors.Join(errs...)
+}
+
+// closeContext closes c, abandoning it when ctx is done before Close
+// returns, as v2's TrackCloser does
+func closeContext(ctx context.Context, c io.Closer) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Close()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
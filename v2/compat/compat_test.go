@@ -0,0 +1,97 @@
+package compat
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type closer struct {
+	closed bool
+}
+
+func (c *closer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestV1CallSites(t *testing.T) {
+	dex := NewDexter()
+	target := NewTarget("v1")
+	c := &closer{}
+	in := make(chan int)
+	target.TrackCloser(c)
+	if err := target.TrackChannel(in); err != nil {
+		t.Fatal(err)
+	}
+	if err := target.TrackChannel(42); err == nil {
+		t.Error("tracked something which isn't a channel")
+	}
+	target.Add(1)
+	go func() {
+		defer target.Done()
+		for range in {
+		}
+	}()
+	migrated := false
+	target.V2().Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		migrated = true
+		return nil
+	})
+	dex.Track(target)
+
+	dex.kill()
+	if !c.closed || !migrated {
+		t.Errorf("closed %v, v2 goroutine returned %v", c.closed, migrated)
+	}
+}
+
+func TestForceExit(t *testing.T) {
+	dex := NewDexter()
+	dex.SetForceKillInterval(10 * time.Millisecond)
+	exited := 0
+	dex.exitFunc = func(code int) { exited = code }
+	target := NewTarget("stuck")
+	target.Add(1)
+	defer target.Done()
+	dex.Track(target)
+
+	dex.kill()
+	if exited != 1 {
+		t.Error("did not force exit")
+	}
+}
+
+type hangingCloser chan struct{}
+
+func (c hangingCloser) Close() error {
+	<-c
+	return nil
+}
+
+func TestForceExitHangingCloser(t *testing.T) {
+	dex := NewDexter()
+	dex.SetForceKillInterval(10 * time.Millisecond)
+	exited := 0
+	dex.exitFunc = func(code int) { exited = code }
+	target := NewTarget("hanging")
+	hang := make(hangingCloser)
+	defer close(hang)
+	target.TrackCloser(hang)
+	dex.Track(target)
+
+	killed := make(chan struct{})
+	go func() {
+		dex.kill()
+		close(killed)
+	}()
+	select {
+	case <-killed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a hanging closer blocked the shutdown")
+	}
+	if exited != 1 {
+		t.Error("did not force exit")
+	}
+}
@@ -0,0 +1,93 @@
+package dexter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// Dexter kills its targets stage by stage on shutdown
+type Dexter struct {
+	signals []os.Signal
+
+	mu     sync.Mutex
+	stages [][]*Target
+	seen   map[*Target]bool
+}
+
+// New returns a Dexter which Wait returns from on signals, by default on
+// os.Interrupt and, where it exists, SIGTERM
+func New(signals ...os.Signal) *Dexter {
+	if len(signals) == 0 {
+		signals = defaultSignals
+	}
+	return &Dexter{signals: signals, seen: map[*Target]bool{}}
+}
+
+// Stage adds a stage of targets, stages are killed in the order they were
+// added and the targets of a stage concurrently.  A target can only be
+// part of one stage.
+func (d *Dexter) Stage(targets ...*Target) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, t := range targets {
+		if d.seen[t] {
+			return fmt.Errorf("dexter: target %s is already staged", t.name)
+		}
+	}
+	for _, t := range targets {
+		d.seen[t] = true
+	}
+	d.stages = append(d.stages, targets)
+	return nil
+}
+
+// Wait blocks until one of the signals arrives, returning it, or until
+// ctx is done, returning ctx.Err()
+func (d *Dexter) Wait(ctx context.Context) (os.Signal, error) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, d.signals...)
+	defer signal.Stop(sigs)
+	select {
+	case sig := <-sigs:
+		return sig, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Shutdown kills the stages in order, giving up once ctx is done.  The
+// errors of all targets are returned joined in a *ShutdownError.
+func (d *Dexter) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	stages := d.stages
+	d.mu.Unlock()
+
+	var errs []error
+	for _, stage := range stages {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, t := range stage {
+			wg.Add(1)
+			go func(t *Target) {
+				defer wg.Done()
+				if err := t.Kill(ctx); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}(t)
+		}
+		wg.Wait()
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ShutdownError{Errs: errs}
+}
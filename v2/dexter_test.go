@@ -0,0 +1,69 @@
+package dexter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShutdownStages(t *testing.T) {
+	dex := New()
+	var order []string
+	ingress, workers := NewTarget("ingress"), NewTarget("workers")
+	in := make(chan int)
+	TrackChan(ingress, in)
+	ingress.Track(ResourceFunc(func(context.Context) error {
+		order = append(order, "ingress")
+		return nil
+	}))
+	workers.Go(func(ctx context.Context) error {
+		for range in {
+		}
+		<-ctx.Done()
+		order = append(order, "workers")
+		return nil
+	})
+	dex.Stage(ingress)
+	dex.Stage(workers)
+
+	if err := dex.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 2 || order[0] != "ingress" || order[1] != "workers" {
+		t.Errorf("unexpected order %v", order)
+	}
+	if err := ingress.Track(ResourceFunc(nil)); err == nil {
+		t.Error("tracked a resource after the target was killed")
+	}
+	if err := dex.Stage(ingress); err == nil {
+		t.Error("staged a target twice")
+	}
+}
+
+func TestShutdownErrors(t *testing.T) {
+	dex := New()
+	boom := errors.New("boom")
+	target := NewTarget("broken")
+	target.Go(func(context.Context) error { return boom })
+	stuck := NewTarget("stuck")
+	stuck.Go(func(context.Context) error {
+		select {}
+	})
+	dex.Stage(target, stuck)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := dex.Shutdown(ctx)
+	if !errors.Is(err, boom) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestWaitContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := New().Wait(ctx); err != context.Canceled {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+// Package dexter is the second major version of the dexter shutdown helper.
+//
+// It keeps v1's model of targets killed in stages and changes its API:
+//
+//   - every blocking call takes a context, Shutdown and Wait give up when
+//     it is done instead of relying on a process wide force kill timer
+//   - every shutdown related call returns an error, closer and goroutine
+//     errors are joined into a *ShutdownError rather than only logged
+//   - ordering is explicit: targets are added to numbered stages with
+//     Stage, targets of the same stage are killed concurrently
+//   - channels are tracked with the generic TrackChan instead of
+//     reflection, so passing something which isn't a channel doesn't compile
+//   - resources close with a context, Resource replaces io.Closer and
+//     TrackCloser adapts existing io.Closers
+//   - goroutines are started with Target.Go, which passes them a context
+//     canceled when the target is killed, instead of Add and Done
+//
+// Usage example:
+//
+//	func main() {
+//		dex := dexter.New()
+//
+//		ingress := dexter.NewTarget("ingress")
+//		ingress.TrackCloser(listener)
+//		requests := make(chan Request)
+//		dexter.TrackChan(ingress, requests)
+//
+//		workers := dexter.NewTarget("workers")
+//		workers.Go(func(ctx context.Context) error {
+//			return work(ctx, requests)
+//		})
+//
+//		dex.Stage(ingress)
+//		dex.Stage(workers)
+//
+//		dex.Wait(context.Background())
+//		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//		defer cancel()
+//		if err := dex.Shutdown(ctx); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// # Migrating from v1
+//
+// Package github.com/ceocoder/dexter/v2/compat implements v1's API on top
+// of this package, so v1 call sites keep working by changing their import
+// path alone.  They can then move to the v2 API one target at a time,
+// compat.Target exposes the v2 target it wraps.
+package dexter
@@ -0,0 +1,39 @@
+package dexter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ShutdownError holds the errors a target returned when it was killed,
+// Shutdown returns the errors of several targets joined
+type ShutdownError struct {
+	Target string
+	Errs   []error
+}
+
+func (e *ShutdownError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	if e.Target == "" {
+		return "dexter: " + strings.Join(msgs, "; ")
+	}
+	return fmt.Sprintf("dexter: target %s: %s", e.Target, strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors for errors.Is and errors.As
+func (e *ShutdownError) Unwrap() []error {
+	return e.Errs
+}
+
+// KilledError is returned when something is tracked on a target which was
+// already killed
+type KilledError struct {
+	Target string
+}
+
+func (e *KilledError) Error() string {
+	return fmt.Sprintf("dexter: target %s was already killed", e.Target)
+}
@@ -0,0 +1,3 @@
+module github.com/ceocoder/dexter/v2
+
+go 1.20
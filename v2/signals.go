@@ -0,0 +1,10 @@
+//go:build !plan9
+
+package dexter
+
+import (
+	"os"
+	"syscall"
+)
+
+var defaultSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
@@ -0,0 +1,5 @@
+package dexter
+
+import "os"
+
+var defaultSignals = []os.Signal{os.Interrupt}
@@ -0,0 +1,147 @@
+package dexter
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// Resource is something a target closes when it is killed.  Close should
+// return early with ctx.Err() once ctx is done.
+type Resource interface {
+	Close(ctx context.Context) error
+}
+
+// ResourceFunc adapts a function to a Resource
+type ResourceFunc func(ctx context.Context) error
+
+// Close calls f
+func (f ResourceFunc) Close(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Target holds the resources and goroutines of one part of the
+// application, which are shut down together
+type Target struct {
+	name string
+
+	mu        sync.Mutex
+	resources []Resource
+	wg        sync.WaitGroup
+	errs      []error
+	ctx       context.Context
+	cancel    context.CancelFunc
+	killed    bool
+}
+
+// NewTarget returns a target called name
+func NewTarget(name string) *Target {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Target{name: name, ctx: ctx, cancel: cancel}
+}
+
+// Name returns the name the target was created with
+func (t *Target) Name() string {
+	return t.name
+}
+
+// Track adds r to the resources closed when the target is killed, in the
+// order they were tracked
+func (t *Target) Track(r Resource) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.killed {
+		return &KilledError{Target: t.name}
+	}
+	t.resources = append(t.resources, r)
+	return nil
+}
+
+// TrackCloser tracks an io.Closer, Close isn't given the context and
+// is abandoned when the context is done before it returns
+func (t *Target) TrackCloser(c io.Closer) error {
+	return t.Track(ResourceFunc(func(ctx context.Context) error {
+		done := make(chan error, 1)
+		go func() {
+			done <- c.Close()
+		}()
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}))
+}
+
+// TrackChan closes ch when t is killed
+func TrackChan[T any](t *Target, ch chan T) error {
+	return t.Track(ResourceFunc(func(context.Context) error {
+		close(ch)
+		return nil
+	}))
+}
+
+// Go runs fn on a new goroutine, the target is only killed once fn
+// returned.  ctx is canceled when the target is killed, right before its
+// resources are closed.  An error returned by fn is part of Kill's error.
+func (t *Target) Go(fn func(ctx context.Context) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.killed {
+		return &KilledError{Target: t.name}
+	}
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		if err := fn(t.ctx); err != nil {
+			t.mu.Lock()
+			t.errs = append(t.errs, err)
+			t.mu.Unlock()
+		}
+	}()
+	return nil
+}
+
+// Kill cancels the target's goroutines, closes its resources and waits for
+// the goroutines to return, giving up once ctx is done.  The errors of the
+// resources and goroutines are returned as a *ShutdownError.  Killing a
+// target again only waits for it.
+func (t *Target) Kill(ctx context.Context) error {
+	t.mu.Lock()
+	resources := t.resources
+	first := !t.killed
+	t.killed = true
+	t.mu.Unlock()
+
+	var errs []error
+	if first {
+		t.cancel()
+		for _, r := range resources {
+			if err := r.Close(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		errs = append(errs, ctx.Err())
+	}
+
+	t.mu.Lock()
+	if first {
+		errs = append(errs, t.errs...)
+	}
+	t.mu.Unlock()
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ShutdownError{Target: t.name, Errs: errs}
+}
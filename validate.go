@@ -0,0 +1,97 @@
+package dexter
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// ValidationError lists every problem Validate found
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "dexter: invalid registration: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the tracked targets for registration mistakes which
+// would only show during a shutdown: nil closers, duplicate target names,
+// channels which can't be closed, ordering constraints naming targets
+// which aren't tracked or contradicting each other and targets with
+// nothing to kill.  It returns a *ValidationError listing all of them, or
+// nil.  WaitAndKill logs what Validate finds before waiting.
+func (d *Dexter) Validate() error {
+	d.mu.Lock()
+	targets := append([]*Target(nil), d.targets...)
+	constraints := d.constraints
+	d.mu.Unlock()
+
+	var problems []string
+	tracked := map[string]int{}
+	for _, target := range targets {
+		if tracked[target.name]++; tracked[target.name] == 2 {
+			problems = append(problems, fmt.Sprintf("several targets named %s", target.name))
+		}
+		problems = append(problems, target.validate()...)
+	}
+	for i, c := range constraints {
+		for _, name := range []string{c.earlier, c.later} {
+			if tracked[name] == 0 {
+				problems = append(problems, fmt.Sprintf("ordering constraint %s before %s names untracked target %s",
+					c.earlier, c.later, name))
+			}
+		}
+		if path := constraintPath(constraints[:i], c.later, c.earlier, map[string]bool{}); path != nil {
+			problems = append(problems, fmt.Sprintf(
+				"ordering constraint %s before %s contradicts the ones before it: %s -> %s",
+				c.earlier, c.later, c.earlier, strings.Join(path, " -> ")))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// validate returns the target's registration problems
+func (t *Target) validate() (problems []string) {
+	t.mu.Lock()
+	monitored, channels := t.monitored, t.channels
+	idle := len(t.locks) == 0 && len(t.synced) == 0 && len(t.quiescers) == 0 && len(t.adopted) == 0
+	t.mu.Unlock()
+
+	for _, closer := range monitored {
+		if isNil(closer) {
+			problems = append(problems, fmt.Sprintf("target %s tracks a nil closer", t.name))
+		}
+	}
+	for _, channel := range channels {
+		v := reflect.ValueOf(channel)
+		switch {
+		case v.IsNil():
+			problems = append(problems, fmt.Sprintf("target %s tracks a nil channel", t.name))
+		case v.Type().ChanDir() == reflect.RecvDir:
+			problems = append(problems, fmt.Sprintf("target %s tracks receive-only channel %s, which can't be closed",
+				t.name, t.channelName(channel)))
+		}
+	}
+	if idle && t.Stats() == (TargetStats{}) {
+		problems = append(problems, fmt.Sprintf("target %s tracks nothing and runs no goroutines", t.name))
+	}
+	return problems
+}
+
+// isNil reports whether closer is nil or a nil pointer, map, func or chan
+func isNil(closer io.Closer) bool {
+	if closer == nil {
+		return true
+	}
+	v := reflect.ValueOf(closer)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Func, reflect.Chan, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package dexter
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	if err := dex.Validate(); err != nil {
+		t.Fatalf("empty dexter invalid: %v", err)
+	}
+
+	var file *os.File
+	db := NewTarget("db")
+	db.TrackCloser(file)
+	events := make(chan int)
+	var recv <-chan int = events
+	db.TrackChannelNamed("events", recv)
+	dex.Track(db)
+	dex.Track(NewTarget("db"))
+	dex.KillAfter("db", "cache")
+
+	var verr *ValidationError
+	if err := dex.Validate(); !errors.As(err, &verr) {
+		t.Fatalf("got %v", err)
+	}
+	want := []string{
+		"target db tracks a nil closer",
+		"target db tracks receive-only channel events",
+		"several targets named db",
+		"target db tracks nothing",
+		"names untracked target cache",
+	}
+	if len(verr.Problems) != len(want) {
+		t.Errorf("got problems %q", verr.Problems)
+	}
+	for i, problem := range verr.Problems {
+		if i < len(want) && !strings.Contains(problem, want[i]) {
+			t.Errorf("problem %d is %q, want %q", i, problem, want[i])
+		}
+	}
+}
+
+func TestValidateContradictingConstraints(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	for _, name := range []string{"http", "db"} {
+		target := NewTarget(name)
+		target.TrackCloser(closerFunc(func() error { return nil }))
+		dex.Track(target)
+	}
+	dex.KillAfter("db", "http")
+	// KillAfter rejects the contradiction, constraints merged from
+	// elsewhere are only checked by Validate
+	dex.constraints = append(dex.constraints, orderConstraint{earlier: "db", later: "http"})
+
+	var verr *ValidationError
+	if err := dex.Validate(); !errors.As(err, &verr) || len(verr.Problems) != 1 {
+		t.Fatalf("got %v", err)
+	}
+	if want := "db -> http -> db"; !strings.Contains(verr.Problems[0], want) {
+		t.Errorf("problem %q does not name the cycle %s", verr.Problems[0], want)
+	}
+}
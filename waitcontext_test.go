@@ -0,0 +1,58 @@
+package dexter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWaitAndKillContextCanceled(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	target := NewTarget("db")
+	dex.Track(target)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := dex.WaitAndKillContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("got %v", err)
+	}
+	if target.State() != TargetRunning || dex.LastReport() != nil {
+		t.Error("killed targets without a shutdown")
+	}
+}
+
+func TestWaitAndKillContextReleasesSignals(t *testing.T) {
+	dex := NewDexter()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := dex.WaitAndKillContext(ctx); err != context.Canceled {
+		t.Errorf("got %v", err)
+	}
+	// panics with a *SignalOwnerError if dex still owns the signals
+	NewDexter().ReleaseSignals()
+}
+
+func TestWaitAndKillContextDeadline(t *testing.T) {
+	dex := NewDexter(WithManualTrigger())
+	dex.SetForceKillInterval(time.Minute)
+	exited := make(chan int, 1)
+	dex.exitFunc = func(code int) { exited <- code }
+	hang := make(chan struct{})
+	defer close(hang)
+	target := NewTarget("stuck")
+	target.TrackCloser(closerFunc(func() error {
+		<-hang
+		return nil
+	}))
+	dex.Track(target)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	dex.Shutdown("test")
+	go dex.WaitAndKillContext(ctx)
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Error("context deadline did not replace the force kill window")
+	}
+}
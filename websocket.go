@@ -0,0 +1,134 @@
+package dexter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// CloseGoingAway is the WebSocket close code sent to clients on shutdown
+const CloseGoingAway = 1001
+
+// WebSocketDrainer tracks hijacked WebSocket connections, which
+// http.Server.Shutdown knows nothing about.  Closing it sends every
+// connection a close frame, gives handlers a grace period to see the
+// client's acknowledgement and closes the TCP connections still left.
+type WebSocketDrainer struct {
+	grace time.Duration
+
+	mu      sync.Mutex
+	conns   map[*wsConn]struct{}
+	closing bool
+	wg      sync.WaitGroup
+}
+
+type wsConn struct {
+	net.Conn
+	sendClose func(code int, reason string) error
+	once      sync.Once
+}
+
+// NewWebSocketDrainer returns a drainer which waits up to grace for clients
+// to acknowledge the close frame, hand it to TrackCloser
+func NewWebSocketDrainer(grace time.Duration) *WebSocketDrainer {
+	return &WebSocketDrainer{
+		grace: grace,
+		conns: map[*wsConn]struct{}{},
+	}
+}
+
+// Track registers an upgraded connection and returns the func to call once
+// the handler is done with it, typically after the client's close frame was
+// received.  sendClose writes a close frame, if the handler writes to conn
+// concurrently it should pass one which is serialized with its own writes.
+// With a nil sendClose the drainer writes the frame to conn directly.
+func (w *WebSocketDrainer) Track(conn net.Conn,
+	sendClose func(code int, reason string) error) (untrack func()) {
+	c := &wsConn{Conn: conn, sendClose: sendClose}
+	if c.sendClose == nil {
+		c.sendClose = c.writeCloseFrame
+	}
+
+	w.mu.Lock()
+	if w.closing {
+		w.mu.Unlock()
+		c.goAway(time.Now().Add(w.grace))
+		conn.Close()
+		return func() {}
+	}
+	defer w.mu.Unlock()
+	w.conns[c] = struct{}{}
+	w.wg.Add(1)
+	return func() {
+		c.once.Do(func() {
+			w.mu.Lock()
+			delete(w.conns, c)
+			w.mu.Unlock()
+			w.wg.Done()
+		})
+	}
+}
+
+// Close sends a close frame to every tracked connection, waits for them to
+// be untracked and closes those that weren't in time.  The frames are sent
+// concurrently within the grace period, a stalled client doesn't hold up
+// the others.
+func (w *WebSocketDrainer) Close() error {
+	w.mu.Lock()
+	w.closing = true
+	conns := make([]*wsConn, 0, len(w.conns))
+	for c := range w.conns {
+		conns = append(conns, c)
+	}
+	w.mu.Unlock()
+
+	deadline := time.Now().Add(w.grace)
+	for _, c := range conns {
+		go c.goAway(deadline)
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-time.After(time.Until(deadline)):
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for c := range w.conns {
+		c.Close()
+	}
+	if n := len(w.conns); n > 0 {
+		return fmt.Errorf("websocket: closed %d connections without a close handshake", n)
+	}
+	return nil
+}
+
+// goAway sends the close frame for shutdown, writes give up at deadline
+func (c *wsConn) goAway(deadline time.Time) {
+	c.SetWriteDeadline(deadline)
+	if err := c.sendClose(CloseGoingAway, "server shutting down"); err != nil {
+		dlog.Printf("Failed to send close frame to %v: %v\n", c.RemoteAddr(), err)
+	}
+}
+
+// writeCloseFrame writes an unmasked close frame, as sent by servers
+func (c *wsConn) writeCloseFrame(code int, reason string) error {
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	frame := make([]byte, 4, 4+len(reason))
+	frame[0] = 0x88 // FIN + close opcode
+	frame[1] = byte(2 + len(reason))
+	binary.BigEndian.PutUint16(frame[2:], uint16(code))
+	frame = append(frame, reason...)
+	_, err := c.Write(frame)
+	return err
+}
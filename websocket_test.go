@@ -0,0 +1,64 @@
+package dexter
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestWebSocketDrainer(t *testing.T) {
+	drainer := NewWebSocketDrainer(50 * time.Millisecond)
+
+	// a client which acknowledges the close frame
+	server, client := net.Pipe()
+	untrack := drainer.Track(server, nil)
+	go func() {
+		frame := make([]byte, 4)
+		io.ReadFull(client, frame)
+		if frame[0] != 0x88 || binary.BigEndian.Uint16(frame[2:]) != CloseGoingAway {
+			t.Errorf("unexpected close frame % x", frame)
+		}
+		io.CopyN(ioutil.Discard, client, int64(frame[1]-2))
+		untrack()
+	}()
+
+	// and one which never does
+	stuck, stuckClient := net.Pipe()
+	drainer.Track(stuck, func(int, string) error { return nil })
+
+	err := drainer.Close()
+	if err == nil || err.Error() != "websocket: closed 1 connections without a close handshake" {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := stuckClient.Read(make([]byte, 1)); err == nil {
+		t.Error("unacknowledged connection was left open")
+	}
+}
+
+func TestWebSocketDrainerStalledClients(t *testing.T) {
+	grace := 50 * time.Millisecond
+	drainer := NewWebSocketDrainer(grace)
+	// clients which never read, their close frames stall
+	for i := 0; i < 4; i++ {
+		server, _ := net.Pipe()
+		drainer.Track(server, nil)
+	}
+
+	started := time.Now()
+	if err := drainer.Close(); err == nil {
+		t.Error("stalled connections reported as drained")
+	}
+	if elapsed := time.Since(started); elapsed > 3*grace {
+		t.Errorf("closing took %v, the stalled frames were sent one by one", elapsed)
+	}
+
+	late, _ := net.Pipe()
+	started = time.Now()
+	drainer.Track(late, nil)
+	if elapsed := time.Since(started); elapsed > 3*grace {
+		t.Errorf("tracking after close took %v", elapsed)
+	}
+}
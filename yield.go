@@ -0,0 +1,53 @@
+package dexter
+
+import (
+	"runtime"
+	"sync"
+)
+
+// closeYield is how many resources are released between yields, 0 means
+// never yield
+var closeYield struct {
+	sync.Mutex
+	every int
+}
+
+// gosched yields the processor, tests replace it
+var gosched = runtime.Gosched
+
+// SetCloseYield makes every target yield the processor after releasing
+// every n funcs, closers and channels, and the kill loop between targets,
+// so on constrained runtimes, such as containers with GOMAXPROCS=1, the
+// goroutines draining the target keep making progress while many resources
+// are closed.  n of 0, the default, never yields.  Like
+// SetCloseConcurrency it applies to the whole process.
+func SetCloseYield(n int) {
+	closeYield.Lock()
+	defer closeYield.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	closeYield.every = n
+}
+
+// yielder yields every so many steps of a release loop
+type yielder struct {
+	every, steps int
+}
+
+// newYielder returns a yielder for the current SetCloseYield setting
+func newYielder() *yielder {
+	closeYield.Lock()
+	defer closeYield.Unlock()
+	return &yielder{every: closeYield.every}
+}
+
+// step counts a released resource, yielding every n of them
+func (y *yielder) step() {
+	if y.every == 0 {
+		return
+	}
+	if y.steps++; y.steps%y.every == 0 {
+		gosched()
+	}
+}
@@ -0,0 +1,25 @@
+package dexter
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestSetCloseYield(t *testing.T) {
+	yields := 0
+	gosched = func() { yields++ }
+	defer func() { gosched = runtime.Gosched }()
+	SetCloseYield(2)
+	defer SetCloseYield(0)
+
+	target := NewTarget("files")
+	for i := 0; i < 5; i++ {
+		target.TrackCloser(closerFunc(func() error { return nil }))
+	}
+	target.TrackChannel(make(chan int))
+	target.Kill()
+	// 2 yields for 5 closers and none for a single channel
+	if yields != 2 {
+		t.Errorf("yielded %d times", yields)
+	}
+}